@@ -0,0 +1,164 @@
+// Query log files written by easylog
+//
+//	easylog-query [flags] [file ...]
+//
+// Files are read in order, or stdin if no file is given, matching entries are printed as they are
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexloser/goaux/easylog"
+	"github.com/alexloser/goaux/easylog/parser"
+)
+
+var (
+	level  = flag.String("level", "", "minimum level, e.g. warn, or a range like info-error, lines not written by easylog are kept without it")
+	since  = flag.String("since", "", `entries at or after time, "2006-01-02 15:04:05", RFC3339, or a duration ago like 1h`)
+	until  = flag.String("until", "", "entries before time, same format as -since")
+	pids   = flag.String("pid", "", "comma separated process ids")
+	file   = flag.String("file", "", `source file pattern, e.g. "fio*" or "main:62"`)
+	grep   = flag.String("grep", "", "regexp matched against message")
+	follow = flag.Bool("f", false, "follow the file like tail -F, across rotation")
+	count  = flag.Bool("count", false, "print counts per level and call site instead of entries")
+	top    = flag.Int("top", 20, "number of call sites printed by -count, 0 for all")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	filter, err := makeFilter()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var stats parser.Stats
+	handle := func(e *parser.Entry) error {
+		if !filter.Match(e) {
+			return nil
+		}
+		if *count {
+			stats.Add(e)
+			return nil
+		}
+		_, err := fmt.Println(e.Raw)
+		return err
+	}
+
+	if *follow {
+		if flag.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "easylog-query: -f needs exactly one file")
+			os.Exit(2)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		err = parser.Follow(ctx, flag.Arg(0), true, 0, handle)
+		if err == context.Canceled {
+			err = nil
+		}
+	} else if flag.NArg() == 0 {
+		err = scan(os.Stdin, handle)
+	} else {
+		for _, name := range flag.Args() {
+			var f *os.File
+			if f, err = os.Open(name); err != nil {
+				break
+			}
+			err = scan(f, handle)
+			f.Close()
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	if *count {
+		stats.Print(os.Stdout, *top)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "easylog-query:", err)
+		os.Exit(1)
+	}
+}
+
+func scan(f *os.File, handle func(*parser.Entry) error) error {
+	s := parser.NewScanner(f)
+	for s.Scan() {
+		if err := handle(s.Entry()); err != nil {
+			return err
+		}
+	}
+	return s.Err()
+}
+
+func makeFilter() (*parser.Filter, error) {
+	f := &parser.Filter{MinLevel: parser.UNKNOWN} // keep lines not written by easylog
+	var err error
+	if *level != "" {
+		low, high, ranged := strings.Cut(*level, "-")
+		if f.MinLevel, err = easylog.ParseLevel(low); err != nil {
+			return nil, err
+		}
+		if ranged {
+			if f.MaxLevel, err = easylog.ParseLevel(high); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if f.Since, err = parseTime(*since); err != nil {
+		return nil, err
+	}
+	if f.Until, err = parseTime(*until); err != nil {
+		return nil, err
+	}
+	if *pids != "" {
+		for _, s := range strings.Split(*pids, ",") {
+			pid, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				return nil, fmt.Errorf("invalid pid %q", s)
+			}
+			f.Pids = append(f.Pids, pid)
+		}
+	}
+	f.File = *file
+	if *grep != "" {
+		if f.Regexp, err = regexp.Compile(*grep); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Parse time of -since and -until, local time is used if no zone is given
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			d = -d
+		}
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{easylog.DEFAULT_TIME_FORMAT, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
@@ -0,0 +1,160 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// Policies when the queue of AsyncWriter is full
+const (
+	OverflowBlock      int = iota // Wait until there is room
+	OverflowDropNewest int = iota // Discard the record being written
+	OverflowDropOldest int = iota // Discard the oldest queued record
+)
+
+// A writer which enqueues every Write into a bounded ring,
+// and a background goroutine writes them into w in batches
+type AsyncWriter struct {
+	w       io.Writer
+	mtx     sync.Mutex
+	cond    *sync.Cond
+	ring    [][]byte
+	head    int
+	count   int
+	policy  int
+	busy    bool
+	closed  bool
+	dropped uint64
+	done    chan struct{}
+}
+
+// Start a background goroutine writing into w, size is capacity of queue in records
+func NewAsyncWriter(w io.Writer, size int, policy int) *AsyncWriter {
+	if size < 1 {
+		size = 1
+	}
+	a := &AsyncWriter{w: w, ring: make([][]byte, size), policy: policy, done: make(chan struct{})}
+	a.cond = sync.NewCond(&a.mtx)
+	go a.loop()
+	return a
+}
+
+// Create a logger writing into w asynchronously
+func NewAsync(w io.Writer, size int, policy int) *EasyLog {
+	return NewWriter(NewAsyncWriter(w, size, policy), false)
+}
+
+// Copy p into queue, p is never split or merged with others
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	for a.count == len(a.ring) && !a.closed {
+		switch a.policy {
+		case OverflowDropNewest:
+			atomic.AddUint64(&a.dropped, 1)
+			return len(p), nil
+		case OverflowDropOldest:
+			atomic.AddUint64(&a.dropped, 1)
+			a.head = (a.head + 1) % len(a.ring)
+			a.count--
+		default:
+			a.cond.Wait()
+		}
+	}
+	if a.closed {
+		return 0, os.ErrClosed
+	}
+
+	tail := (a.head + a.count) % len(a.ring)
+	a.ring[tail] = append(a.ring[tail][:0], p...)
+	a.count++
+	a.cond.Broadcast()
+	return len(p), nil
+}
+
+func (a *AsyncWriter) loop() {
+	defer close(a.done)
+	var batch bytes.Buffer
+	for {
+		a.mtx.Lock()
+		for a.count == 0 && !a.closed {
+			a.cond.Wait()
+		}
+		if a.count == 0 && a.closed {
+			a.mtx.Unlock()
+			return
+		}
+		batch.Reset()
+		for ; a.count > 0; a.count-- {
+			batch.Write(a.ring[a.head])
+			a.head = (a.head + 1) % len(a.ring)
+		}
+		a.busy = true
+		a.cond.Broadcast()
+		a.mtx.Unlock()
+
+		a.w.Write(batch.Bytes())
+
+		a.mtx.Lock()
+		a.busy = false
+		a.cond.Broadcast()
+		a.mtx.Unlock()
+	}
+}
+
+// Wait until all queued records are written
+func (a *AsyncWriter) drain() {
+	a.mtx.Lock()
+	for a.count > 0 || a.busy {
+		a.cond.Wait()
+	}
+	a.mtx.Unlock()
+}
+
+// Drain the queue and sync the underlying writer
+func (a *AsyncWriter) Sync() error {
+	a.drain()
+	if s, ok := a.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Number of records discarded by the overflow policy
+func (a *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// Drain the queue, stop the goroutine and close the underlying writer
+func (a *AsyncWriter) Close() error {
+	a.mtx.Lock()
+	if a.closed {
+		a.mtx.Unlock()
+		return os.ErrClosed
+	}
+	a.closed = true
+	a.cond.Broadcast()
+	a.mtx.Unlock()
+	<-a.done
+
+	if s, ok := a.w.(interface{ Sync() error }); ok {
+		s.Sync()
+	}
+	return closeWriter(a.w)
+}
+
+// Close writers owned by logger, files passed by caller are left open
+func closeWriter(w io.Writer) error {
+	if _, ok := w.(*os.File); ok {
+		return nil
+	}
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
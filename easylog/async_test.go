@@ -0,0 +1,92 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A writer slow enough to fill the queue
+type slowWriter struct {
+	mtx sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.buf.Write(p)
+}
+
+func TestAsyncWriter(t *testing.T) {
+	out := &slowWriter{}
+	log := NewAsync(out, 1024, OverflowBlock)
+	for i := 0; i < 100; i++ {
+		log.Info("line", i)
+	}
+	log.Flush()
+	if n := strings.Count(out.buf.String(), "\n"); n != 100 {
+		t.Error(n)
+	}
+	if !strings.HasSuffix(out.buf.String(), " line 99\n") {
+		t.Error(out.buf.String())
+	}
+	log.Close()
+	if _, err := log.Sinks()[0].(*WriterSink).Writer().Write([]byte("closed\n")); err == nil {
+		t.Fail()
+	}
+
+	for _, policy := range []int{OverflowDropNewest, OverflowDropOldest} {
+		out = &slowWriter{}
+		w := NewAsyncWriter(out, 4, policy)
+		for i := 0; i < 1000; i++ {
+			w.Write([]byte("x\n"))
+		}
+		w.Close()
+		if n := uint64(strings.Count(out.buf.String(), "\n")); w.Dropped() == 0 || n+w.Dropped() != 1000 {
+			t.Error(policy, n, w.Dropped())
+		}
+	}
+}
+
+func benchmarkFile(b *testing.B) *os.File {
+	f, err := os.Create(filepath.Join(b.TempDir(), "bench.log"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { f.Close() })
+	return f
+}
+
+func BenchmarkSync(b *testing.B) {
+	log := New(benchmarkFile(b), false)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Info("benchmark", "sync", 12345)
+		}
+	})
+}
+
+func BenchmarkDummyMutex(b *testing.B) {
+	log := New(benchmarkFile(b), true)
+	for i := 0; i < b.N; i++ {
+		log.Info("benchmark", "unsafe", 12345)
+	}
+}
+
+func BenchmarkAsync(b *testing.B) {
+	log := NewAsync(benchmarkFile(b), 4096, OverflowBlock)
+	defer log.Close()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			log.Info("benchmark", "async", 12345)
+		}
+	})
+	log.Flush()
+}
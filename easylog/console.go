@@ -0,0 +1,75 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ANSI escape codes
+const (
+	colorReset   = "\x1b[0m"
+	colorGray    = "\x1b[90m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorRed     = "\x1b[31m"
+	colorBoldRed = "\x1b[1;31m"
+	colorCyan    = "\x1b[36m"
+)
+
+var levelColors = []string{colorGray, colorGreen, colorYellow, colorRed, colorBoldRed, colorBoldRed}
+
+func levelColor(level int) string {
+	if level >= 0 && level < len(levelColors) {
+		return levelColors[level]
+	}
+	return colorReset
+}
+
+var defaultLayout = MustCompileLayout(DEFAULT_LAYOUT)
+
+// Text format for console, level tag and keys of fields are colored if Color is true
+// Output is the same as TextEncoder if Color is false
+type ConsoleEncoder struct {
+	Layout      *Layout // nil means DEFAULT_LAYOUT
+	Color       bool
+	ColorFields bool
+}
+
+func (e *ConsoleEncoder) Encode(buf *bytes.Buffer, r *Record) {
+	layout := e.Layout
+	if layout == nil {
+		layout = defaultLayout
+	}
+	if !e.Color {
+		layout.Append(buf, r)
+		writeTextBody(buf, r, "")
+		return
+	}
+	layout.append(buf, r, levelColor(r.Level))
+	if e.ColorFields {
+		writeTextBody(buf, r, colorCyan)
+	} else {
+		writeTextBody(buf, r, "")
+	}
+}
+
+// Whether to write colors into f, NO_COLOR disables and FORCE_COLOR enables colors,
+// or else only terminals get colors
+func UseColor(f *os.File) bool {
+	if v := os.Getenv("NO_COLOR"); v != "" {
+		return false
+	}
+	if v := strings.ToLower(os.Getenv("FORCE_COLOR")); v != "" && v != "0" && v != "false" {
+		return true
+	}
+	return f != nil && term.IsTerminal(int(f.Fd()))
+}
+
+// Create sink on console, colored only if UseColor(f) is true
+func NewConsoleSink(f *os.File, level int) *WriterSink {
+	return NewSink(f, level, &ConsoleEncoder{Color: UseColor(f), ColorFields: true})
+}
@@ -0,0 +1,64 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestConsoleEncoder(t *testing.T) {
+	r := &Record{
+		Time:   time.Date(2019, 4, 12, 18, 1, 29, 244000000, time.Local),
+		Level:  E,
+		Pid:    6460,
+		File:   "/src/app/main.go",
+		Line:   62,
+		Msg:    "failed",
+		Fields: []interface{}{"code", 7},
+	}
+
+	var plain, text bytes.Buffer
+	(&ConsoleEncoder{}).Encode(&plain, r)
+	(&TextEncoder{}).Encode(&text, r)
+	if plain.String() != text.String() {
+		t.Error(plain.String(), text.String())
+	}
+
+	var buf bytes.Buffer
+	(&ConsoleEncoder{Color: true, ColorFields: true}).Encode(&buf, r)
+	want := "[2019-04-12 18:01:29.244 \x1b[31mE\x1b[0m] 6460 main:62 failed \x1b[36mcode\x1b[0m=7\n"
+	if buf.String() != want {
+		t.Errorf("%q", buf.String())
+	}
+}
+
+func TestUseColor(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+	if UseColor(w) {
+		t.Error("pipe")
+	}
+	t.Setenv("FORCE_COLOR", "1")
+	if !UseColor(w) {
+		t.Error("FORCE_COLOR")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if UseColor(w) {
+		t.Error("NO_COLOR")
+	}
+
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "")
+	if _, ok := New(w, false).Sinks()[0].(*WriterSink).enc.(*TextEncoder); !ok {
+		t.Error("pipe should get text format")
+	}
+}
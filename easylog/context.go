@@ -0,0 +1,110 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"context"
+	"sync"
+)
+
+// Get a value such as request id or trace id from context
+type ContextExtractor func(ctx context.Context) (string, bool)
+
+var extractors struct {
+	mtx   sync.RWMutex
+	keys  []string
+	funcs []ContextExtractor
+}
+
+// Register an extractor, its value is written as key=value after file:line
+// Registering the same key again replaces the old extractor
+func RegisterContextExtractor(key string, fn ContextExtractor) {
+	extractors.mtx.Lock()
+	defer extractors.mtx.Unlock()
+	for i, k := range extractors.keys {
+		if k == key {
+			extractors.funcs[i] = fn
+			return
+		}
+	}
+	extractors.keys = append(extractors.keys, key)
+	extractors.funcs = append(extractors.funcs, fn)
+}
+
+// Extract key/value pairs from ctx by all extractors
+func extractContext(ctx context.Context) []interface{} {
+	extractors.mtx.RLock()
+	defer extractors.mtx.RUnlock()
+	var kv []interface{}
+	for i, fn := range extractors.funcs {
+		if v, ok := fn(ctx); ok {
+			kv = append(kv, extractors.keys[i], v)
+		}
+	}
+	return kv
+}
+
+// Create an extractor reading ctx.Value(key) as string
+func ValueExtractor(key interface{}) ContextExtractor {
+	return func(ctx context.Context) (string, bool) {
+		switch v := ctx.Value(key).(type) {
+		case nil:
+			return "", false
+		case string:
+			return v, v != ""
+		default:
+			return fieldString(v), true
+		}
+	}
+}
+
+// On level info with values from ctx
+func (l *EasyLog) InfoCtx(ctx context.Context, v ...interface{}) {
+	l.LogCtx(ctx, nil, 2, I, v...)
+}
+
+// On level debug with values from ctx
+func (l *EasyLog) DebugCtx(ctx context.Context, v ...interface{}) {
+	l.LogCtx(ctx, nil, 2, D, v...)
+}
+
+// On level warning with values from ctx
+func (l *EasyLog) WarnCtx(ctx context.Context, v ...interface{}) {
+	l.LogCtx(ctx, nil, 2, W, v...)
+}
+
+// On level error with values from ctx
+func (l *EasyLog) ErrorCtx(ctx context.Context, v ...interface{}) {
+	l.LogCtx(ctx, nil, 2, E, v...)
+}
+
+// On level fatal with values from ctx, then flush, run exit hooks and exit
+func (l *EasyLog) FatalCtx(ctx context.Context, v ...interface{}) {
+	l.LogCtx(ctx, nil, 2, F, v...)
+	l.exit()
+}
+
+// On level info with values from ctx
+func InfoCtx(ctx context.Context, v ...interface{}) {
+	elog.LogCtx(ctx, nil, 2, I, v...)
+}
+
+// On level debug with values from ctx
+func DebugCtx(ctx context.Context, v ...interface{}) {
+	elog.LogCtx(ctx, nil, 2, D, v...)
+}
+
+// On level warning with values from ctx
+func WarnCtx(ctx context.Context, v ...interface{}) {
+	elog.LogCtx(ctx, nil, 2, W, v...)
+}
+
+// On level error with values from ctx
+func ErrorCtx(ctx context.Context, v ...interface{}) {
+	elog.LogCtx(ctx, nil, 2, E, v...)
+}
+
+// On level fatal with values from ctx, then flush, run exit hooks and exit
+func FatalCtx(ctx context.Context, v ...interface{}) {
+	elog.LogCtx(ctx, nil, 2, F, v...)
+	elog.exit()
+}
@@ -0,0 +1,52 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type ctxKey string
+
+// A value which counts formatting
+type counter struct{ n *int }
+
+func (c counter) String() string {
+	*c.n++
+	return "counted"
+}
+
+func TestLogf(t *testing.T) {
+	var out bytes.Buffer
+	log := NewWriter(&out, false)
+
+	n := 0
+	log.Debugf("filtered %v", counter{&n})
+	log.Infof("%d-%s %v", 1, "a", counter{&n})
+	if n != 1 || !strings.HasSuffix(out.String(), "context_test:27 1-a counted\n") {
+		t.Error(n, out.String())
+	}
+}
+
+func TestLogCtx(t *testing.T) {
+	RegisterContextExtractor("req", ValueExtractor(ctxKey("req")))
+	RegisterContextExtractor("trace", ValueExtractor(ctxKey("trace")))
+
+	var out bytes.Buffer
+	log := NewWriter(&out, false)
+	ctx := context.WithValue(context.Background(), ctxKey("req"), "r-1")
+	log.InfoCtx(ctx, "hello")
+	if !strings.HasSuffix(out.String(), "context_test:40 req=r-1 hello\n") {
+		t.Error(out.String())
+	}
+
+	out.Reset()
+	log.SetEncoder(&JSONEncoder{})
+	ctx = context.WithValue(ctx, ctxKey("trace"), "t-2")
+	log.WarnCtx(ctx, "hello")
+	if !strings.Contains(out.String(), `,"req":"r-1","trace":"t-2","msg":"hello"}`) {
+		t.Error(out.String())
+	}
+}
@@ -3,7 +3,9 @@ package easylog
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"strconv"
@@ -12,21 +14,29 @@ import (
 	"time"
 )
 
-// Default global logger
-var elog *EasyLog = nil
+// Default global logger, writes into stderr until Initialize replaces it
+var elog = New(os.Stderr, false)
 
-// Only 5 log levels
+func init() {
+	Register("default", elog)
+}
+
+// Only 5 log levels, and P for panic
 const (
 	D int = iota // Debug
 	I int = iota // Info
 	W int = iota // Warning
 	E int = iota // Error
 	F int = iota // Fatal or Final
+	P int = iota // Panic
 )
 
 // Internal lock for IO
 var once_flag sync.Once
 
+// Process id, cached for every line
+var pid = os.Getpid()
+
 // For better performance in singel thread
 type dummy_mutex struct{}
 
@@ -38,55 +48,195 @@ func (m *dummy_mutex) Unlock() {
 	// do nothing
 }
 
-// This simple logger has only one member io.Writer for writing data
+// This simple logger writes every record into a group of sinks
 type EasyLog struct {
-	mtx   sync.Locker
-	file  *os.File
-	buf   bytes.Buffer
-	level int
+	mtx       sync.Locker
+	sinks     []Sink
+	level     int
+	modules   []moduleLevel
+	pcModules map[uintptr]int
+	nocaller  bool
+	sampling  [P + 1]*sampling
+	sites     map[siteKey]*siteCount
+	// earliest end of interval with suppressed lines, and the timer writing summaries then
+	summaryDue   time.Time
+	summaryTimer *time.Timer
+	msg          bytes.Buffer
+	rec          Record
+}
+
+// Create an independent logger, if unsafe is true, dummy mutex will be used instead of sync.Mutex
+// Level tags are colored if file is a terminal, see UseColor
+func New(file *os.File, unsafe bool) *EasyLog {
+	if UseColor(file) {
+		return NewSinks(unsafe, NewConsoleSink(file, D))
+	}
+	return NewWriter(file, unsafe)
+}
+
+// Create an independent logger on any writer, e.g. a RotateWriter
+func NewWriter(w io.Writer, unsafe bool) *EasyLog {
+	return NewSinks(unsafe, NewSink(w, D, &TextEncoder{}))
+}
+
+// Create an independent logger writing into all sinks
+func NewSinks(unsafe bool, sinks ...Sink) *EasyLog {
+	l := &EasyLog{mtx: &sync.Mutex{}, sinks: sinks, level: envLevel.level}
+	if unsafe {
+		l.mtx = &dummy_mutex{}
+	}
+	l.setModules(envLevel.modules)
+	return l
 }
 
 // Init global logger, if unsafe is true, dummy mutex will be used instead of sync.Mutex
+// The first call replaces the default logger on stderr, later calls return the same one
 func Initialize(file *os.File, unsafe bool) *EasyLog {
-	if elog != nil {
+	initialized := true
+	once_flag.Do(func() {
+		initialized = false
+		elog = New(file, unsafe)
+		Register("default", elog)
+	})
+	if initialized {
 		Warn("EasyLog has already initialized!!!")
 	}
 
-	once_flag.Do(func() {
-		if unsafe {
-			elog = &EasyLog{&dummy_mutex{}, file, bytes.Buffer{}, I}
-		} else {
-			elog = &EasyLog{&sync.Mutex{}, file, bytes.Buffer{}, I}
-		}
-	})
+	return elog
+}
 
+// Get the default global logger, which writes into stderr before Initialize
+func Default() *EasyLog {
 	return elog
 }
 
 // Set log level
-func SetLevel(level int) {
-	elog.mtx.Lock()
-	defer elog.mtx.Unlock()
-	elog.level = level
+func (l *EasyLog) SetLevel(level int) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.level = level
 }
 
 // Get log level
-func GetLevel() int {
-	return elog.level
+func (l *EasyLog) GetLevel() int {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.level
+}
+
+// Enable or disable looking up source file and line of every record, enabled by default
+// Disable it for speed if no sink prints the caller
+func (l *EasyLog) SetCaller(enabled bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.nocaller = !enabled
+}
+
+// Add one more sink, records are written into sinks in order of adding
+func (l *EasyLog) AddSink(s Sink) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.sinks = append(l.sinks, s)
+}
+
+// Remove sink added before, it's not closed
+func (l *EasyLog) RemoveSink(s Sink) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for i, sink := range l.sinks {
+		if sink == s {
+			l.sinks = append(l.sinks[:i:i], l.sinks[i+1:]...)
+			return
+		}
+	}
+}
+
+// Get all sinks
+func (l *EasyLog) Sinks() []Sink {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return append([]Sink(nil), l.sinks...)
+}
+
+// Set encoder of the first sink, which is created by New or NewFile
+func (l *EasyLog) SetEncoder(enc Encoder) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if len(l.sinks) > 0 {
+		if s, ok := l.sinks[0].(interface{ SetEncoder(Encoder) }); ok {
+			s.SetEncoder(enc)
+		}
+	}
 }
 
 // Sync all data in buffer into opened log-file
+func (l *EasyLog) Flush() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.flushSummaries()
+	for _, s := range l.sinks {
+		syncSink(s)
+	}
+}
+
+// Flush and close all sinks, the *os.File passed to New is left open
+func (l *EasyLog) Close() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.flushSummaries()
+	if l.summaryTimer != nil {
+		l.summaryTimer.Stop()
+	}
+	var err error
+	for _, s := range l.sinks {
+		if e := closeSink(s); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// Set log level of global logger
+func SetLevel(level int) {
+	elog.SetLevel(level)
+}
+
+// Get log level of global logger
+func GetLevel() int {
+	return elog.GetLevel()
+}
+
+// Add one more sink into global logger
+func AddSink(s Sink) {
+	elog.AddSink(s)
+}
+
+// Set encoder of global logger
+func SetEncoder(enc Encoder) {
+	elog.SetEncoder(enc)
+}
+
+// Sync all data in buffer of global logger into opened log-file
 func Flush() {
-	elog.mtx.Lock()
-	defer elog.mtx.Unlock()
-	elog.file.Sync()
+	elog.Flush()
+}
+
+// Flush and close the global logger
+func Close() error {
+	return elog.Close()
 }
 
 // Join all log prefix
 func Join(buf *bytes.Buffer, level int, name string, line int) string {
 	buf.Reset()
+	joinPrefix(buf, time.Now(), level, pid, name, line)
+	return buf.String()
+}
+
+func joinPrefix(buf *bytes.Buffer, t time.Time, level int, pid int, name string, line int) {
+	var scratch [32]byte
 	buf.WriteByte('[')
-	buf.WriteString(time.Now().String()[:23])
+	buf.Write(t.AppendFormat(scratch[:0], DEFAULT_TIME_FORMAT))
 
 	switch level {
 	case D:
@@ -99,81 +249,325 @@ func Join(buf *bytes.Buffer, level int, name string, line int) string {
 		buf.WriteString(" E] ")
 	case F:
 		buf.WriteString(" F] ")
+	case P:
+		buf.WriteString(" P] ")
 	}
 
-	buf.WriteString(strconv.Itoa(os.Getpid()))
+	buf.WriteString(strconv.Itoa(pid))
 	buf.WriteByte(' ')
 	buf.WriteString(name)
 	buf.WriteByte(':')
 	buf.WriteString(strconv.Itoa(line))
 	buf.WriteByte(' ')
+}
 
-	return buf.String()
+// Get short name of source file, e.g. /src/foo/bar.go -> bar
+func shortName(path string) string {
+	if v, ok := shortNames.Load(path); ok {
+		return v.(string)
+	}
+	name := path
+	if pos := strings.LastIndex(name, "/"); pos != -1 {
+		name = name[pos+1:]
+		if strings.HasSuffix(name, ".go") {
+			name = strings.Replace(name, ".go", "", 1)
+		}
+	}
+	shortNames.Store(path, name)
+	return name
 }
 
 // Standard log style printer, it's multithread safe, output format like:
 // 2019-04-12 18:01:29.244 I [6460 main.go:62] this is a info
-func Log(file *os.File, degree int, level int, vargs ...interface{}) {
-	elog.mtx.Lock()
-	defer elog.mtx.Unlock()
-	if level < elog.level {
+// If file is nil, the record goes to all sinks, or else to file only in text format
+// The whole line is passed to file in one Write call, so it's never split
+func (l *EasyLog) Log(file io.Writer, degree int, level int, vargs ...interface{}) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if !l.allow(level, degree) {
 		return
 	}
 
-	_, name, line, _ := runtime.Caller(degree)
+	l.msg.Reset()
+	for i, v := range vargs {
+		if i > 0 {
+			l.msg.WriteByte(' ')
+		}
+		fmt.Fprint(&l.msg, v)
+	}
 
-	if pos := strings.LastIndex(name, "/"); pos != -1 {
-		name = name[pos+1:]
-		if strings.HasSuffix(name, ".go") {
-			name = strings.Replace(name, ".go", "", 1)
+	l.write(file, degree, level, l.msg.String(), nil, nil)
+}
+
+// Printf style printer, format is not called if level is filtered
+func (l *EasyLog) Logf(file io.Writer, degree int, level int, format string, vargs ...interface{}) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if !l.allow(level, degree) {
+		return
+	}
+
+	l.msg.Reset()
+	fmt.Fprintf(&l.msg, format, vargs...)
+
+	l.write(file, degree, level, l.msg.String(), nil, nil)
+}
+
+// Printer with values from ctx by registered extractors
+func (l *EasyLog) LogCtx(ctx context.Context, file io.Writer, degree int, level int, vargs ...interface{}) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if !l.allow(level, degree) {
+		return
+	}
+
+	l.msg.Reset()
+	for i, v := range vargs {
+		if i > 0 {
+			l.msg.WriteByte(' ')
 		}
+		fmt.Fprint(&l.msg, v)
+	}
+
+	l.write(file, degree, level, l.msg.String(), nil, ctx)
+}
+
+// Structured log printer, kv is a list of key/value pairs
+func (l *EasyLog) LogKV(file io.Writer, degree int, level int, msg string, kv ...interface{}) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if !l.allow(level, degree) {
+		return
 	}
 
-	fmt.Fprintf(file, Join(&elog.buf, level, name, line))
+	l.write(file, degree, level, msg, kv, nil)
+}
+
+// Encode one record and write it, must be called with mtx held
+func (l *EasyLog) write(file io.Writer, degree int, level int, msg string, kv []interface{}, ctx context.Context) {
+	if l.nocaller {
+		l.emit(file, 0, "", 0, level, msg, kv, ctx)
+		return
+	}
+	pc, name, line, _ := runtime.Caller(degree + 1)
+	l.writeAt(file, pc, name, line, level, msg, kv, ctx)
+}
 
-	for _, v := range vargs {
-		fmt.Fprintf(file, "%v ", v)
+// Sample and write record of caller, must be called with mtx held
+func (l *EasyLog) writeAt(file io.Writer, pc uintptr, name string, line int, level int, msg string, kv []interface{}, ctx context.Context) {
+	if !l.summaryDue.IsZero() {
+		if now := time.Now(); !now.Before(l.summaryDue) {
+			l.dueSummaries(now)
+		}
+	}
+	if file == nil && level >= D && level <= P && l.sampling[level] != nil {
+		if !l.sample(l.sampling[level], pc, name, line, level) {
+			return
+		}
 	}
+	l.emit(file, pc, name, line, level, msg, kv, ctx)
+}
 
-	fmt.Fprintf(file, "\n")
+// Write record of caller pc, source file name and line, must be called with mtx held
+func (l *EasyLog) emit(file io.Writer, pc uintptr, name string, line int, level int, msg string, kv []interface{}, ctx context.Context) {
+	l.rec = Record{
+		Time:   time.Now(),
+		Level:  level,
+		Pid:    pid,
+		PC:     pc,
+		File:   name,
+		Line:   line,
+		Msg:    msg,
+		Fields: kv,
+	}
+	if ctx != nil {
+		l.rec.Context = extractContext(ctx)
+	}
+	if file != nil {
+		var buf bytes.Buffer
+		(&TextEncoder{}).Encode(&buf, &l.rec)
+		file.Write(buf.Bytes())
+		return
+	}
+	for _, s := range l.sinks {
+		if level >= s.Level() {
+			s.Emit(&l.rec)
+		}
+	}
+}
+
+// On level info
+func (l *EasyLog) Info(v ...interface{}) {
+	l.Log(nil, 2, I, v...)
+}
+
+// On level debug
+func (l *EasyLog) Debug(v ...interface{}) {
+	l.Log(nil, 2, D, v...)
+}
+
+// On level warning
+func (l *EasyLog) Warn(v ...interface{}) {
+	l.Log(nil, 2, W, v...)
+}
+
+// On level error
+func (l *EasyLog) Error(v ...interface{}) {
+	l.Log(nil, 2, E, v...)
+}
+
+// On level fatal, then flush, run exit hooks and exit
+func (l *EasyLog) Fatal(v ...interface{}) {
+	l.Log(nil, 2, F, v...)
+	l.exit()
+}
+
+// On level info with key/value pairs
+func (l *EasyLog) InfoKV(msg string, kv ...interface{}) {
+	l.LogKV(nil, 2, I, msg, kv...)
+}
+
+// On level debug with key/value pairs
+func (l *EasyLog) DebugKV(msg string, kv ...interface{}) {
+	l.LogKV(nil, 2, D, msg, kv...)
+}
+
+// On level warning with key/value pairs
+func (l *EasyLog) WarnKV(msg string, kv ...interface{}) {
+	l.LogKV(nil, 2, W, msg, kv...)
+}
+
+// On level error with key/value pairs
+func (l *EasyLog) ErrorKV(msg string, kv ...interface{}) {
+	l.LogKV(nil, 2, E, msg, kv...)
+}
+
+// On level fatal with key/value pairs, then flush, run exit hooks and exit
+func (l *EasyLog) FatalKV(msg string, kv ...interface{}) {
+	l.LogKV(nil, 2, F, msg, kv...)
+	l.exit()
+}
+
+// On level info with printf style format
+func (l *EasyLog) Infof(format string, v ...interface{}) {
+	l.Logf(nil, 2, I, format, v...)
+}
+
+// On level debug with printf style format
+func (l *EasyLog) Debugf(format string, v ...interface{}) {
+	l.Logf(nil, 2, D, format, v...)
+}
+
+// On level warning with printf style format
+func (l *EasyLog) Warnf(format string, v ...interface{}) {
+	l.Logf(nil, 2, W, format, v...)
+}
+
+// On level error with printf style format
+func (l *EasyLog) Errorf(format string, v ...interface{}) {
+	l.Logf(nil, 2, E, format, v...)
+}
+
+// On level fatal with printf style format, then flush, run exit hooks and exit
+func (l *EasyLog) Fatalf(format string, v ...interface{}) {
+	l.Logf(nil, 2, F, format, v...)
+	l.exit()
+}
+
+// Standard log style printer of global logger
+func Log(file io.Writer, degree int, level int, vargs ...interface{}) {
+	elog.Log(file, degree+1, level, vargs...)
 }
 
 // On level info
 func Info(v ...interface{}) {
-	Log(elog.file, 2, I, v...)
+	elog.Log(nil, 2, I, v...)
 }
 
 // On level debug
 func Debug(v ...interface{}) {
-	Log(elog.file, 2, D, v...)
+	elog.Log(nil, 2, D, v...)
 }
 
 // On level warning
 func Warn(v ...interface{}) {
-	Log(elog.file, 2, W, v...)
+	elog.Log(nil, 2, W, v...)
 }
 
 // On level error
 func Error(v ...interface{}) {
-	Log(elog.file, 2, E, v...)
+	elog.Log(nil, 2, E, v...)
 }
 
-// On level fatal
+// On level fatal, then flush, run exit hooks and exit
 func Fatal(v ...interface{}) {
-	Log(elog.file, 2, F, v...)
+	elog.Log(nil, 2, F, v...)
+	elog.exit()
+}
+
+// On level info with key/value pairs
+func InfoKV(msg string, kv ...interface{}) {
+	elog.LogKV(nil, 2, I, msg, kv...)
+}
+
+// On level debug with key/value pairs
+func DebugKV(msg string, kv ...interface{}) {
+	elog.LogKV(nil, 2, D, msg, kv...)
+}
+
+// On level warning with key/value pairs
+func WarnKV(msg string, kv ...interface{}) {
+	elog.LogKV(nil, 2, W, msg, kv...)
+}
+
+// On level error with key/value pairs
+func ErrorKV(msg string, kv ...interface{}) {
+	elog.LogKV(nil, 2, E, msg, kv...)
+}
+
+// On level fatal with key/value pairs, then flush, run exit hooks and exit
+func FatalKV(msg string, kv ...interface{}) {
+	elog.LogKV(nil, 2, F, msg, kv...)
+	elog.exit()
+}
+
+// On level info with printf style format
+func Infof(format string, v ...interface{}) {
+	elog.Logf(nil, 2, I, format, v...)
+}
+
+// On level debug with printf style format
+func Debugf(format string, v ...interface{}) {
+	elog.Logf(nil, 2, D, format, v...)
+}
+
+// On level warning with printf style format
+func Warnf(format string, v ...interface{}) {
+	elog.Logf(nil, 2, W, format, v...)
+}
+
+// On level error with printf style format
+func Errorf(format string, v ...interface{}) {
+	elog.Logf(nil, 2, E, format, v...)
+}
+
+// On level fatal with printf style format, then flush, run exit hooks and exit
+func Fatalf(format string, v ...interface{}) {
+	elog.Logf(nil, 2, F, format, v...)
+	elog.exit()
 }
 
 // Safe assert(means exit when false)
 func Assert(condition bool) {
 	if false == condition {
-		Log(os.Stderr, 2, E, "Assert Failed!")
-		os.Exit(-1)
+		elog.Log(os.Stderr, 2, E, "Assert Failed!")
+		elog.exit()
 	}
 }
 
 // UnSafe assert(means no exit when false)
 func AssertNoExit(condition bool) {
 	if false == condition {
-		Log(os.Stderr, 2, E, "Assert Failed!")
+		elog.Log(os.Stderr, 2, E, "Assert Failed!")
 	}
 }
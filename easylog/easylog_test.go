@@ -5,11 +5,24 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
+func TestDefault(t *testing.T) {
+	if Default() == nil || Lookup("default") != Default() {
+		t.Fatal("no default logger before Initialize")
+	}
+	// package functions work before Initialize
+	SetLevel(GetLevel())
+	Debug("default", "logger")
+	Flush()
+}
+
 func TestEasyLog(t *testing.T) {
 	var log = Initialize(os.Stdout, true)
+	AssertNoExit(Default() == log && Lookup("default") == log)
 	SetLevel(0)
 
 	Debug("debug", "foo")
@@ -41,3 +54,79 @@ func TestEasyLog(t *testing.T) {
 	fmt.Printf("%s OK\n", Join(&b, 1, "easylog_test.go", 32))
 
 }
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	f1, _ := os.Create(filepath.Join(dir, "a.log"))
+	f2, _ := os.Create(filepath.Join(dir, "b.log"))
+	defer f1.Close()
+	defer f2.Close()
+
+	a := New(f1, false)
+	b := New(f2, true)
+	b.SetLevel(E)
+
+	a.Info("from", "a")
+	b.Info("from", "b")
+	b.Error("error", "b")
+	a.Flush()
+	b.Flush()
+
+	da, _ := os.ReadFile(f1.Name())
+	db, _ := os.ReadFile(f2.Name())
+	if !strings.Contains(string(da), "I] ") || !strings.Contains(string(da), "easylog_test:") {
+		t.Error(string(da))
+	}
+	if strings.Contains(string(db), "from b") || !strings.Contains(string(db), "error b") {
+		t.Error(string(db))
+	}
+	if a.GetLevel() != I || b.GetLevel() != E {
+		t.Fail()
+	}
+}
+
+func TestRotateWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := NewRotateWriter(path, RotateOptions{MaxSize: 256, Compress: true, MaxFiles: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := NewWriter(w, false)
+	for i := 0; i < 40; i++ {
+		log.Info("rotate", i)
+	}
+	w.Close()
+
+	rotated, _ := filepath.Glob(path + ".*.gz")
+	if len(rotated) != 2 {
+		t.Error(rotated)
+	}
+	data, _ := os.ReadFile(path)
+	if len(data) == 0 || len(data) > 256 || !strings.HasSuffix(string(data), "\n") {
+		t.Error(string(data))
+	}
+	if _, err := w.Write([]byte("closed\n")); err == nil {
+		t.Fail()
+	}
+}
+
+func TestRotateWriterCloseError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewRotateWriter(path, RotateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	// closing the old file fails, a new handle is opened anyway
+	w.file.Close()
+	if err := w.Rotate(); err == nil {
+		t.Error("error of Close is lost")
+	}
+	if _, err := w.Write([]byte("after\n")); err != nil {
+		t.Error(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "after\n" {
+		t.Error(string(data))
+	}
+}
@@ -0,0 +1,123 @@
+// Helpers capturing logs of easylog in tests
+package easylogtest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alexloser/goaux/easylog"
+)
+
+// Default number of records kept for one test
+const CAPTURE_SIZE = 4096
+
+// Captured logs of one test, dumped by tb.Log only if the test fails
+type Recorder struct {
+	tb   testing.TB
+	ring *easylog.RingSink
+	Log  *easylog.EasyLog
+}
+
+// Create a logger of level D for one test
+func New(tb testing.TB) *Recorder {
+	tb.Helper()
+	ring := easylog.NewRingSink(CAPTURE_SIZE, easylog.D)
+	l := easylog.NewSinks(false, ring)
+	l.SetLevel(easylog.D)
+	r := &Recorder{tb, ring, l}
+	tb.Cleanup(r.dump)
+	return r
+}
+
+// Capture records of an existing logger, e.g. easylog.Default(), until the test ends
+// Level of l is not changed
+func Capture(tb testing.TB, l *easylog.EasyLog) *Recorder {
+	tb.Helper()
+	ring := easylog.NewRingSink(CAPTURE_SIZE, easylog.D)
+	l.AddSink(ring)
+	r := &Recorder{tb, ring, l}
+	tb.Cleanup(func() {
+		l.RemoveSink(ring)
+		r.dump()
+	})
+	return r
+}
+
+func (r *Recorder) dump() {
+	if !r.tb.Failed() {
+		return
+	}
+	var buf bytes.Buffer
+	enc := &easylog.TextEncoder{}
+	for _, rec := range r.ring.Records() {
+		enc.Encode(&buf, &rec)
+	}
+	r.tb.Logf("captured logs:\n%s", buf.String())
+}
+
+// All captured records, the oldest first
+func (r *Recorder) Records() []easylog.Record {
+	return r.ring.Records()
+}
+
+// Messages of all captured records
+func (r *Recorder) Messages() []string {
+	records := r.ring.Records()
+	msgs := make([]string, len(records))
+	for i, rec := range records {
+		msgs[i] = rec.Msg
+	}
+	return msgs
+}
+
+// Records of level whose message contains substr
+func (r *Recorder) Find(level int, substr string) []easylog.Record {
+	var found []easylog.Record
+	for _, rec := range r.ring.Records() {
+		if rec.Level == level && strings.Contains(rec.Msg, substr) {
+			found = append(found, rec)
+		}
+	}
+	return found
+}
+
+// Whether any record of level contains substr in its message
+func (r *Recorder) Contains(level int, substr string) bool {
+	return len(r.Find(level, substr)) > 0
+}
+
+// Fail the test if no record of level contains substr
+func (r *Recorder) AssertLogged(level int, substr string) {
+	r.tb.Helper()
+	if !r.Contains(level, substr) {
+		r.tb.Errorf("no %s log contains %q", easylog.LevelName(level), substr)
+	}
+}
+
+// Fail the test if any record of level contains substr
+func (r *Recorder) AssertNotLogged(level int, substr string) {
+	r.tb.Helper()
+	if r.Contains(level, substr) {
+		r.tb.Errorf("unexpected %s log contains %q", easylog.LevelName(level), substr)
+	}
+}
+
+// Fail the test unless exactly n records are of level
+func (r *Recorder) AssertCount(level int, n int) {
+	r.tb.Helper()
+	count := 0
+	for _, rec := range r.ring.Records() {
+		if rec.Level == level {
+			count++
+		}
+	}
+	if count != n {
+		r.tb.Errorf("%d %s logs, want %d", count, easylog.LevelName(level), n)
+	}
+}
+
+// Remove all captured records
+func (r *Recorder) Reset() {
+	r.ring.Reset()
+}
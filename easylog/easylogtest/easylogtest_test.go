@@ -0,0 +1,70 @@
+// Helpers capturing logs of easylog in tests
+package easylogtest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alexloser/goaux/easylog"
+)
+
+// Fake testing.TB recording failures and logs
+type fakeTB struct {
+	testing.TB
+	failed  bool
+	logs    []string
+	cleanup []func()
+}
+
+func (f *fakeTB) Helper()                                   {}
+func (f *fakeTB) Failed() bool                              { return f.failed }
+func (f *fakeTB) Errorf(format string, args ...interface{}) { f.failed = true }
+func (f *fakeTB) Logf(format string, args ...interface{})   { f.logs = append(f.logs, args[0].(string)) }
+func (f *fakeTB) Cleanup(fn func())                         { f.cleanup = append(f.cleanup, fn) }
+
+func (f *fakeTB) finish() {
+	for i := len(f.cleanup) - 1; i >= 0; i-- {
+		f.cleanup[i]()
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	rec := New(t)
+	rec.Log.Debug("starting", "job")
+	rec.Log.WarnKV("slow", "ms", 1200)
+	rec.AssertLogged(easylog.D, "starting job")
+	rec.AssertLogged(easylog.W, "slow")
+	rec.AssertNotLogged(easylog.E, "slow")
+	rec.AssertCount(easylog.W, 1)
+	if msgs := rec.Messages(); len(msgs) != 2 || rec.Find(easylog.W, "")[0].Fields[1] != 1200 {
+		t.Error(msgs)
+	}
+	rec.Reset()
+	if len(rec.Records()) != 0 {
+		t.Fail()
+	}
+}
+
+func TestDumpOnFailure(t *testing.T) {
+	var out bytes.Buffer
+	l := easylog.NewWriter(&out, false)
+
+	tb := &fakeTB{}
+	rec := Capture(tb, l)
+	l.Info("passed")
+	rec.AssertLogged(easylog.I, "passed")
+	tb.finish()
+	if tb.failed || len(tb.logs) != 0 || len(l.Sinks()) != 1 {
+		t.Error(tb.logs)
+	}
+
+	tb = &fakeTB{}
+	rec = Capture(tb, l)
+	l.Info("failed")
+	rec.AssertLogged(easylog.E, "failed")
+	tb.finish()
+	if !tb.failed || len(tb.logs) != 1 || !strings.Contains(tb.logs[0], " I] ") {
+		t.Error(tb.logs)
+	}
+}
@@ -0,0 +1,287 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// One log line before encoding
+type Record struct {
+	Time    time.Time
+	Level   int
+	Pid     int
+	PC      uintptr // program counter of caller, 0 if unknown
+	File    string  // full path of source file
+	Line    int
+	Msg     string
+	Fields  []interface{} // key/value pairs
+	Context []interface{} // key/value pairs from context.Context, part of prefix
+}
+
+// Encoder writes one record and the trailing linebreak into buf
+type Encoder interface {
+	Encode(buf *bytes.Buffer, r *Record)
+}
+
+var levelNames = []string{"debug", "info", "warn", "error", "fatal", "panic"}
+
+// Get lower case name of level, e.g. "info"
+func LevelName(level int) string {
+	if level >= 0 && level < len(levelNames) {
+		return levelNames[level]
+	}
+	return strconv.Itoa(level)
+}
+
+// Key of field at index i, non-string keys are formatted
+func fieldKey(kv []interface{}, i int) string {
+	if i+1 == len(kv) {
+		return "EXTRA"
+	}
+	if k, ok := kv[i].(string); ok {
+		return k
+	}
+	return fmt.Sprint(kv[i])
+}
+
+// Value of field at index i, the last one of odd list is a value without key
+func fieldValue(kv []interface{}, i int) interface{} {
+	if i+1 == len(kv) {
+		return kv[i]
+	}
+	return kv[i+1]
+}
+
+// Format value as plain text
+func fieldString(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case error:
+		return x.Error()
+	case fmt.Stringer:
+		return x.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// The default format: [2019-04-12 18:01:29.244 I] 6460 main:62 msg key=value
+// Layout replaces the prefix before msg if it's not nil
+type TextEncoder struct {
+	Layout *Layout
+}
+
+// Create text encoder with a prefix layout, see Layout for directives
+func NewTextEncoder(layout string) (*TextEncoder, error) {
+	l, err := CompileLayout(layout)
+	if err != nil {
+		return nil, err
+	}
+	return &TextEncoder{l}, nil
+}
+
+func (e *TextEncoder) Encode(buf *bytes.Buffer, r *Record) {
+	if e.Layout != nil {
+		e.Layout.Append(buf, r)
+	} else {
+		joinPrefix(buf, r.Time, r.Level, r.Pid, shortName(r.File), r.Line)
+	}
+	writeTextBody(buf, r, "")
+}
+
+// Write context, msg and fields after prefix, keys are wrapped in ANSI color if it's not empty
+// Keys and values are escaped like logfmt, so a field never spans lines
+func writeTextBody(buf *bytes.Buffer, r *Record, color string) {
+	writeKey := func(k string) {
+		if color != "" {
+			buf.WriteString(color)
+			writeLogfmtKey(buf, k)
+			buf.WriteString(colorReset)
+		} else {
+			writeLogfmtKey(buf, k)
+		}
+		buf.WriteByte('=')
+	}
+	for i := 0; i < len(r.Context); i += 2 {
+		writeKey(fieldKey(r.Context, i))
+		writeLogfmtValue(buf, fieldString(fieldValue(r.Context, i)))
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(r.Msg)
+	for i := 0; i < len(r.Fields); i += 2 {
+		buf.WriteByte(' ')
+		writeKey(fieldKey(r.Fields, i))
+		writeLogfmtValue(buf, fieldString(fieldValue(r.Fields, i)))
+	}
+	buf.WriteByte('\n')
+}
+
+// JSON lines format, one object per line
+type JSONEncoder struct{}
+
+func (e *JSONEncoder) Encode(buf *bytes.Buffer, r *Record) {
+	buf.WriteString(`{"time":"`)
+	buf.WriteString(r.Time.Format(time.RFC3339Nano))
+	buf.WriteString(`","level":"`)
+	buf.WriteString(LevelName(r.Level))
+	buf.WriteString(`","pid":`)
+	buf.WriteString(strconv.Itoa(r.Pid))
+	buf.WriteString(`,"caller":`)
+	writeJSONString(buf, filepath.Base(r.File)+":"+strconv.Itoa(r.Line))
+	for i := 0; i < len(r.Context); i += 2 {
+		buf.WriteByte(',')
+		writeJSONString(buf, fieldKey(r.Context, i))
+		buf.WriteByte(':')
+		writeJSONValue(buf, fieldValue(r.Context, i))
+	}
+	buf.WriteString(`,"msg":`)
+	writeJSONString(buf, r.Msg)
+	for i := 0; i < len(r.Fields); i += 2 {
+		buf.WriteByte(',')
+		writeJSONString(buf, fieldKey(r.Fields, i))
+		buf.WriteByte(':')
+		writeJSONValue(buf, fieldValue(r.Fields, i))
+	}
+	buf.WriteString("}\n")
+}
+
+func writeJSONValue(buf *bytes.Buffer, v interface{}) {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		writeJSONString(buf, x)
+	case bool:
+		buf.WriteString(strconv.FormatBool(x))
+	case int:
+		buf.WriteString(strconv.Itoa(x))
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(x), 10))
+	case int64:
+		buf.WriteString(strconv.FormatInt(x, 10))
+	case uint:
+		buf.WriteString(strconv.FormatUint(uint64(x), 10))
+	case uint32:
+		buf.WriteString(strconv.FormatUint(uint64(x), 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(x, 10))
+	case float32, float64:
+		if b, err := json.Marshal(x); err == nil {
+			buf.Write(b)
+		} else {
+			writeJSONString(buf, fmt.Sprint(x))
+		}
+	case error, fmt.Stringer:
+		writeJSONString(buf, fieldString(x))
+	default:
+		if b, err := json.Marshal(x); err == nil {
+			buf.Write(b)
+		} else {
+			writeJSONString(buf, fmt.Sprint(x))
+		}
+	}
+}
+
+const hexDigits = "0123456789abcdef"
+
+// Write s as a quoted JSON string, invalid utf8 is replaced
+func writeJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case c == '\n':
+				buf.WriteString(`\n`)
+			case c == '\r':
+				buf.WriteString(`\r`)
+			case c == '\t':
+				buf.WriteString(`\t`)
+			case c < 0x20:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xF])
+			default:
+				buf.WriteByte(c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(`\ufffd`)
+		} else {
+			buf.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	buf.WriteByte('"')
+}
+
+// Logfmt format: time=... level=info pid=6460 caller=main.go:62 msg="hello world" key=value
+type LogfmtEncoder struct{}
+
+func (e *LogfmtEncoder) Encode(buf *bytes.Buffer, r *Record) {
+	buf.WriteString("time=")
+	buf.WriteString(r.Time.Format(time.RFC3339Nano))
+	buf.WriteString(" level=")
+	buf.WriteString(LevelName(r.Level))
+	buf.WriteString(" pid=")
+	buf.WriteString(strconv.Itoa(r.Pid))
+	buf.WriteString(" caller=")
+	writeLogfmtValue(buf, filepath.Base(r.File)+":"+strconv.Itoa(r.Line))
+	for i := 0; i < len(r.Context); i += 2 {
+		buf.WriteByte(' ')
+		writeLogfmtKey(buf, fieldKey(r.Context, i))
+		buf.WriteByte('=')
+		writeLogfmtValue(buf, fieldString(fieldValue(r.Context, i)))
+	}
+	buf.WriteString(" msg=")
+	writeLogfmtValue(buf, r.Msg)
+	for i := 0; i < len(r.Fields); i += 2 {
+		buf.WriteByte(' ')
+		writeLogfmtKey(buf, fieldKey(r.Fields, i))
+		buf.WriteByte('=')
+		writeLogfmtValue(buf, fieldString(fieldValue(r.Fields, i)))
+	}
+	buf.WriteByte('\n')
+}
+
+// Keys can not be quoted, so invalid chars are replaced by '_'
+func writeLogfmtKey(buf *bytes.Buffer, k string) {
+	if k == "" {
+		buf.WriteByte('_')
+		return
+	}
+	for _, c := range k {
+		if c <= ' ' || c == '=' || c == '"' || c == utf8.RuneError {
+			buf.WriteByte('_')
+		} else {
+			buf.WriteRune(c)
+		}
+	}
+}
+
+// Quote value only if it's empty or contains space, '=', '"' or control chars
+func writeLogfmtValue(buf *bytes.Buffer, v string) {
+	if v == "" {
+		buf.WriteString(`""`)
+		return
+	}
+	for _, c := range v {
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == utf8.RuneError || c == 0x7f {
+			writeJSONString(buf, v)
+			return
+		}
+	}
+	buf.WriteString(v)
+}
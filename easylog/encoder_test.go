@@ -0,0 +1,62 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncoders(t *testing.T) {
+	r := &Record{
+		Time:  time.Date(2019, 4, 12, 18, 1, 29, 244000000, time.UTC),
+		Level: W,
+		Pid:   6460,
+		File:  "/src/app/main.go",
+		Line:  62,
+		Msg:   "say \"hi\"\n",
+		Fields: []interface{}{"user", 42, "latency", 1500 * time.Millisecond, "err", errors.New("a=b"),
+			"note", "two\nlines \"quoted\"", "odd"},
+	}
+
+	var buf bytes.Buffer
+	(&TextEncoder{}).Encode(&buf, r)
+	if buf.String() != "[2019-04-12 18:01:29.244 W] 6460 main:62 say \"hi\"\n user=42 latency=1.5s err=\"a=b\" note=\"two\\nlines \\\"quoted\\\"\" EXTRA=odd\n" {
+		t.Error(buf.String())
+	}
+
+	buf.Reset()
+	(&JSONEncoder{}).Encode(&buf, r)
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	if m["level"] != "warn" || m["msg"] != r.Msg || m["user"] != 42.0 || m["latency"] != "1.5s" ||
+		m["caller"] != "main.go:62" || m["pid"] != 6460.0 || m["EXTRA"] != "odd" {
+		t.Error(buf.String())
+	}
+
+	buf.Reset()
+	(&LogfmtEncoder{}).Encode(&buf, r)
+	want := `time=2019-04-12T18:01:29.244Z level=warn pid=6460 caller=main.go:62 msg="say \"hi\"\n" user=42 latency=1.5s err="a=b" note="two\nlines \"quoted\"" EXTRA=odd` + "\n"
+	if buf.String() != want {
+		t.Error(buf.String())
+	}
+}
+
+func TestLogKV(t *testing.T) {
+	var out bytes.Buffer
+	log := NewWriter(&out, false)
+	log.SetEncoder(&JSONEncoder{})
+	log.InfoKV("request done", "path", "/api", "status", 200)
+	log.DebugKV("filtered")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"path":"/api","status":200}`) ||
+		!strings.Contains(lines[0], `"caller":"encoder_test.go:`) {
+		t.Error(out.String())
+	}
+}
@@ -0,0 +1,165 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/alexloser/goaux/system"
+)
+
+// Replaced in tests
+var exitFunc = os.Exit
+
+var hooks struct {
+	mtx   sync.Mutex
+	funcs []func()
+}
+
+// Register a function called before Fatal and Assert exit the process
+// Hooks are called in reverse order of registering, like defer
+func RegisterExitHook(fn func()) {
+	hooks.mtx.Lock()
+	defer hooks.mtx.Unlock()
+	hooks.funcs = append(hooks.funcs, fn)
+}
+
+func runExitHooks() {
+	hooks.mtx.Lock()
+	funcs := hooks.funcs
+	hooks.funcs = nil
+	hooks.mtx.Unlock()
+	for i := len(funcs) - 1; i >= 0; i-- {
+		funcs[i]()
+	}
+}
+
+// Flush all sinks of l and global logger, run exit hooks, then exit with 1
+// Sinks are flushed again after hooks, which may log too
+func (l *EasyLog) exit() {
+	flush := func() {
+		l.Flush()
+		if elog != nil && elog != l {
+			elog.Flush()
+		}
+	}
+	flush()
+	runExitHooks()
+	flush()
+	exitFunc(1)
+}
+
+// On level panic, then panic with the message
+func (l *EasyLog) Panic(v ...interface{}) {
+	l.Log(nil, 2, P, v...)
+	l.Flush()
+	panic(sprint(v...))
+}
+
+// On level panic with key/value pairs, then panic with msg
+func (l *EasyLog) PanicKV(msg string, kv ...interface{}) {
+	l.LogKV(nil, 2, P, msg, kv...)
+	l.Flush()
+	panic(msg)
+}
+
+// On level panic with printf style format, then panic with the message
+func (l *EasyLog) Panicf(format string, v ...interface{}) {
+	l.Logf(nil, 2, P, format, v...)
+	l.Flush()
+	panic(fmt.Sprintf(format, v...))
+}
+
+// Log the panic with stacks of all goroutines, then panic again
+// Must be called directly by defer: defer log.Recover()
+func (l *EasyLog) Recover() {
+	if r := recover(); r != nil {
+		l.recovered(r, false)
+	}
+}
+
+// Log the panic with stacks of all goroutines, then flush and exit like Fatal
+// Must be called directly by defer: defer log.RecoverAndExit()
+func (l *EasyLog) RecoverAndExit() {
+	if r := recover(); r != nil {
+		l.recovered(r, true)
+	}
+}
+
+func (l *EasyLog) recovered(r interface{}, exit bool) {
+	l.mtx.Lock()
+	if P >= l.level {
+		pc, name, line := panicCaller()
+		l.emit(nil, pc, name, line, P, fmt.Sprint("panic: ", r), []interface{}{"stack", system.StackInfo(true)}, nil)
+	}
+	l.mtx.Unlock()
+	if exit {
+		l.exit()
+		return
+	}
+	l.Flush()
+	panic(r)
+}
+
+// Join values with spaces as Log does
+func sprint(v ...interface{}) string {
+	s := fmt.Sprintln(v...)
+	return s[:len(s)-1]
+}
+
+// Find the frame which panicked, it's the first one out of runtime after gopanic
+func panicCaller() (uintptr, string, int) {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	panicking := false
+	for {
+		f, more := frames.Next()
+		if f.Function == "runtime.gopanic" {
+			panicking = true
+		} else if panicking && !strings.HasPrefix(f.Function, "runtime.") {
+			return f.PC, f.File, f.Line
+		}
+		if !more {
+			return 0, "???", 0
+		}
+	}
+}
+
+// On level panic, then panic with the message
+func Panic(v ...interface{}) {
+	elog.Log(nil, 2, P, v...)
+	elog.Flush()
+	panic(sprint(v...))
+}
+
+// On level panic with key/value pairs, then panic with msg
+func PanicKV(msg string, kv ...interface{}) {
+	elog.LogKV(nil, 2, P, msg, kv...)
+	elog.Flush()
+	panic(msg)
+}
+
+// On level panic with printf style format, then panic with the message
+func Panicf(format string, v ...interface{}) {
+	elog.Logf(nil, 2, P, format, v...)
+	elog.Flush()
+	panic(fmt.Sprintf(format, v...))
+}
+
+// Recover by global logger, must be called directly by defer
+func Recover() {
+	if r := recover(); r != nil {
+		elog.recovered(r, false)
+	}
+}
+
+// Recover by global logger and exit, must be called directly by defer
+func RecoverAndExit() {
+	if r := recover(); r != nil {
+		elog.recovered(r, true)
+	}
+}
@@ -0,0 +1,68 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFatal(t *testing.T) {
+	code := 0
+	exitFunc = func(c int) { code = c }
+	defer func() { exitFunc = os.Exit }()
+
+	var out bytes.Buffer
+	log := NewWriter(&out, false)
+	var order []int
+	RegisterExitHook(func() { order = append(order, 1) })
+	RegisterExitHook(func() { order = append(order, 2) })
+	// a hook logging through an async writer, the line must be flushed before exit
+	async := &slowWriter{}
+	RegisterExitHook(func() { log.Info("cleanup") })
+	RegisterExitHook(func() { log.AddSink(NewSink(NewAsyncWriter(async, 16, OverflowBlock), D, &TextEncoder{})) })
+
+	log.Fatal("fatal", "error")
+	if code != 1 || len(order) != 2 || order[0] != 2 || !strings.Contains(out.String(), " F] ") {
+		t.Error(code, order, out.String())
+	}
+	async.mtx.Lock()
+	defer async.mtx.Unlock()
+	if !strings.Contains(async.buf.String(), " cleanup\n") {
+		t.Error(async.buf.String())
+	}
+}
+
+func TestPanicRecover(t *testing.T) {
+	var out bytes.Buffer
+	log := NewWriter(&out, false)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom 1" {
+				t.Error(r)
+			}
+		}()
+		log.Panic("boom", 1)
+	}()
+	if !strings.Contains(out.String(), " P] ") || !strings.Contains(out.String(), "exit_test:") {
+		t.Error(out.String())
+	}
+
+	out.Reset()
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("not panic again")
+			}
+		}()
+		defer log.Recover()
+		var m map[string]int
+		m["x"] = 1
+	}()
+	if !strings.Contains(out.String(), "panic: assignment to entry in nil map") ||
+		!strings.Contains(out.String(), "exit_test:62") || !strings.Contains(out.String(), "goroutine") {
+		t.Error(out.String())
+	}
+}
@@ -0,0 +1,118 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Native socket of systemd-journald
+const JOURNAL_SOCKET = "/run/systemd/journal/socket"
+
+// Sink sending records to journald by its native protocol,
+// fields of records become journal fields with upper case names
+// Records larger than the datagram limit of socket are not sent
+type JournalSink struct {
+	mtx   sync.Mutex
+	conn  *net.UnixConn
+	ident string
+	level int
+	buf   bytes.Buffer
+}
+
+// Create journald sink, addr is JOURNAL_SOCKET if empty and identifier is base of os.Args[0] if empty
+func NewJournalSink(addr string, identifier string, level int) (*JournalSink, error) {
+	if addr == "" {
+		addr = JOURNAL_SOCKET
+	}
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: addr, Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	return &JournalSink{conn: conn, ident: identifier, level: level}, nil
+}
+
+func (s *JournalSink) Level() int {
+	return s.level
+}
+
+func (s *JournalSink) Emit(r *Record) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.conn == nil {
+		return os.ErrClosed
+	}
+
+	s.buf.Reset()
+	writeJournalField(&s.buf, "MESSAGE", r.Msg)
+	writeJournalField(&s.buf, "PRIORITY", strconv.Itoa(Severity(r.Level)))
+	writeJournalField(&s.buf, "SYSLOG_IDENTIFIER", s.ident)
+	writeJournalField(&s.buf, "SYSLOG_PID", strconv.Itoa(r.Pid))
+	if r.File != "" {
+		writeJournalField(&s.buf, "CODE_FILE", r.File)
+		writeJournalField(&s.buf, "CODE_LINE", strconv.Itoa(r.Line))
+		if r.PC != 0 {
+			fn, _ := funcName(r.PC)
+			writeJournalField(&s.buf, "CODE_FUNC", fn)
+		}
+	}
+	for _, kv := range [][]interface{}{r.Context, r.Fields} {
+		for i := 0; i < len(kv); i += 2 {
+			writeJournalField(&s.buf, journalName(fieldKey(kv, i)), fieldString(fieldValue(kv, i)))
+		}
+	}
+
+	_, err := s.conn.Write(s.buf.Bytes())
+	return err
+}
+
+// Field names are upper case letters, digits and '_', and can not start with '_' or digit
+func journalName(key string) string {
+	name := []byte(strings.ToUpper(key))
+	for i, c := range name {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			name[i] = '_'
+		}
+	}
+	if len(name) == 0 || name[0] == '_' || (name[0] >= '0' && name[0] <= '9') {
+		return "F" + string(name)
+	}
+	return string(name)
+}
+
+// Write KEY=value, or KEY with length prefixed value if value contains linebreak
+func writeJournalField(buf *bytes.Buffer, key string, value string) {
+	buf.WriteString(key)
+	if strings.IndexByte(value, '\n') < 0 {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	buf.Write(size[:])
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+func (s *JournalSink) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
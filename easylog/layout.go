@@ -0,0 +1,267 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alexloser/goaux/utils"
+)
+
+// Layout of default TextEncoder, same as Join
+const DEFAULT_LAYOUT = "[%time %L] %pid %file:%line "
+
+// Default format of %time
+const DEFAULT_TIME_FORMAT = "2006-01-02 15:04:05.000"
+
+// Prefix layout compiled from a template, directives are:
+//
+//	%time{format}    local time, format is a Go layout or utils.Strftime directives like %Y-%m-%d
+//	%utctime{format} same as %time but in UTC
+//	%L %level %LEVEL level as letter, lower name or upper name
+//	%pid %gid        process id, goroutine id
+//	%file %base %path source file as "main", "main.go" or full path
+//	%line            source line
+//	%func %pkg       function as "pkg.Func", full import path of package
+//	%%               a single %
+//
+// Time format defaults to DEFAULT_TIME_FORMAT
+type Layout struct {
+	parts  []layoutPart
+	caller bool
+}
+
+type layoutPart struct {
+	kind int
+	text string // literal text or time format
+	strf bool   // text is a utils.Strftime format
+	utc  bool
+}
+
+const (
+	partText = iota
+	partTime
+	partLetter
+	partLevel
+	partLEVEL
+	partPid
+	partGid
+	partFile
+	partBase
+	partPath
+	partLine
+	partFunc
+	partPkg
+)
+
+var directives = map[string]int{
+	"time":    partTime,
+	"utctime": partTime,
+	"L":       partLetter,
+	"level":   partLevel,
+	"LEVEL":   partLEVEL,
+	"pid":     partPid,
+	"gid":     partGid,
+	"file":    partFile,
+	"base":    partBase,
+	"path":    partPath,
+	"line":    partLine,
+	"func":    partFunc,
+	"pkg":     partPkg,
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// Compile layout template once for all lines
+func CompileLayout(layout string) (*Layout, error) {
+	l := &Layout{}
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			l.parts = append(l.parts, layoutPart{kind: partText, text: text.String()})
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' {
+			text.WriteByte(layout[i])
+			continue
+		}
+		if i+1 < len(layout) && layout[i+1] == '%' {
+			text.WriteByte('%')
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(layout) && isLetter(layout[j]) {
+			j++
+		}
+		name := layout[i+1 : j]
+		kind, ok := directives[name]
+		if !ok {
+			return nil, fmt.Errorf("easylog: unknown directive %%%s in layout at %d", name, i)
+		}
+		part := layoutPart{kind: kind}
+		if kind == partTime {
+			part.utc = name == "utctime"
+			part.text = DEFAULT_TIME_FORMAT
+			if j < len(layout) && layout[j] == '{' {
+				end := strings.IndexByte(layout[j:], '}')
+				if end < 0 {
+					return nil, fmt.Errorf("easylog: unclosed { in layout at %d", j)
+				}
+				part.text = layout[j+1 : j+end]
+				part.strf = strings.Contains(part.text, "%")
+				j += end + 1
+			}
+		}
+		switch kind {
+		case partFile, partBase, partPath, partLine, partFunc, partPkg:
+			l.caller = true
+		}
+		flush()
+		l.parts = append(l.parts, part)
+		i = j - 1
+	}
+	flush()
+	return l, nil
+}
+
+// Compile layout and panic on error, for global variables
+func MustCompileLayout(layout string) *Layout {
+	l, err := CompileLayout(layout)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// Whether the layout uses source file, line or function
+func (l *Layout) NeedCaller() bool {
+	return l.caller
+}
+
+// Append prefix of record into buf
+func (l *Layout) Append(buf *bytes.Buffer, r *Record) {
+	l.append(buf, r, "")
+}
+
+// Append prefix with level wrapped in ANSI color if color is not empty
+func (l *Layout) append(buf *bytes.Buffer, r *Record, color string) {
+	var scratch [64]byte
+	for i := range l.parts {
+		p := &l.parts[i]
+		switch p.kind {
+		case partText:
+			buf.WriteString(p.text)
+		case partTime:
+			t := r.Time
+			if p.utc {
+				t = t.UTC()
+			}
+			if p.strf {
+				buf.WriteString(utils.Strftime(&t, p.text))
+			} else {
+				buf.Write(t.AppendFormat(scratch[:0], p.text))
+			}
+		case partLetter, partLevel, partLEVEL:
+			if color != "" {
+				buf.WriteString(color)
+			}
+			switch p.kind {
+			case partLetter:
+				buf.WriteString(levelLetter(r.Level))
+			case partLevel:
+				buf.WriteString(LevelName(r.Level))
+			default:
+				buf.WriteString(strings.ToUpper(LevelName(r.Level)))
+			}
+			if color != "" {
+				buf.WriteString(colorReset)
+			}
+		case partPid:
+			buf.Write(strconv.AppendInt(scratch[:0], int64(r.Pid), 10))
+		case partGid:
+			buf.Write(strconv.AppendUint(scratch[:0], goroutineID(), 10))
+		case partFile:
+			buf.WriteString(shortName(r.File))
+		case partBase:
+			buf.WriteString(baseName(r.File))
+		case partPath:
+			buf.WriteString(r.File)
+		case partLine:
+			buf.Write(strconv.AppendInt(scratch[:0], int64(r.Line), 10))
+		case partFunc:
+			fn, _ := funcName(r.PC)
+			buf.WriteString(fn)
+		case partPkg:
+			_, pkg := funcName(r.PC)
+			buf.WriteString(pkg)
+		}
+	}
+}
+
+var levelLetters = []string{"D", "I", "W", "E", "F", "P"}
+
+func levelLetter(level int) string {
+	if level >= 0 && level < len(levelLetters) {
+		return levelLetters[level]
+	}
+	return "?"
+}
+
+// Get base name of path, e.g. /src/foo/bar.go -> bar.go
+func baseName(path string) string {
+	if pos := strings.LastIndexByte(path, '/'); pos != -1 {
+		return path[pos+1:]
+	}
+	return path
+}
+
+// Cache of source file names and function names
+var (
+	shortNames sync.Map // string -> string
+	funcNames  sync.Map // uintptr -> [2]string
+)
+
+// Get short function name like "pkg.Func" and full package path of pc
+func funcName(pc uintptr) (string, string) {
+	if v, ok := funcNames.Load(pc); ok {
+		names := v.([2]string)
+		return names[0], names[1]
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "???", "???"
+	}
+	full := fn.Name()
+	short, pkg := full, full
+	slash := strings.LastIndexByte(full, '/')
+	if slash >= 0 {
+		short = full[slash+1:]
+	}
+	if dot := strings.IndexByte(full[slash+1:], '.'); dot >= 0 {
+		pkg = full[:slash+1+dot]
+	}
+	funcNames.Store(pc, [2]string{short, pkg})
+	return short, pkg
+}
+
+// Get id of current goroutine from the head of its stack, "goroutine 18 [running]:"
+func goroutineID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	s := bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+	if i := bytes.IndexByte(s, ' '); i > 0 {
+		s = s[:i]
+	}
+	id, _ := strconv.ParseUint(string(s), 10, 64)
+	return id
+}
@@ -0,0 +1,70 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLayout(t *testing.T) {
+	r := &Record{
+		Time:  time.Date(2019, 4, 12, 18, 1, 29, 200000000, time.FixedZone("X", 3600)),
+		Level: E,
+		Pid:   6460,
+		File:  "/src/app/main.go",
+		Line:  62,
+	}
+	cases := map[string]string{
+		DEFAULT_LAYOUT: "[2019-04-12 18:01:29.200 E] 6460 main:62 ",
+		"%utctime{2006-01-02T15:04:05.000Z07:00} %LEVEL %level %base:%line 100%%": "2019-04-12T17:01:29.200Z ERROR error main.go:62 100%",
+		"%time{%Y/%m/%d %H:%M:%S} %path":                                          "2019/04/12 18:01:29 /src/app/main.go",
+	}
+	for layout, want := range cases {
+		l, err := CompileLayout(layout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		l.Append(&buf, r)
+		if buf.String() != want {
+			t.Error(layout, buf.String())
+		}
+	}
+
+	for _, bad := range []string{"%foo", "%time{abc"} {
+		if _, err := CompileLayout(bad); err == nil {
+			t.Error(bad)
+		}
+	}
+
+	var buf bytes.Buffer
+	Join(&buf, I, "main", 1)
+	if buf.Len() != len("[2019-04-12 18:01:29.200 I] ")+len(strconv.Itoa(pid))+len(" main:1 ") {
+		t.Error(buf.String())
+	}
+}
+
+func TestLoggerLayout(t *testing.T) {
+	var out bytes.Buffer
+	enc, err := NewTextEncoder("%level %func %pkg %gid|")
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := NewSinks(false, NewSink(&out, D, enc))
+	log.Info("hello")
+	want := "info easylog.TestLoggerLayout github.com/alexloser/goaux/easylog "
+	if !strings.HasPrefix(out.String(), want) || !strings.HasSuffix(out.String(), "|hello\n") {
+		t.Error(out.String())
+	}
+
+	out.Reset()
+	log.SetCaller(false)
+	log.SetEncoder(&TextEncoder{})
+	log.Info("hello")
+	if !strings.HasSuffix(out.String(), " :0 hello\n") {
+		t.Error(out.String())
+	}
+}
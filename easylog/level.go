@@ -0,0 +1,303 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Environment variable of default level spec for all loggers, e.g. "fio=debug,*=info"
+const LEVEL_ENV = "EASYLOG_LEVEL"
+
+// Parsed from LEVEL_ENV at init
+var envLevel = struct {
+	level   int
+	modules []moduleLevel
+}{level: I}
+
+func init() {
+	if spec, ok := os.LookupEnv(LEVEL_ENV); ok {
+		level, modules, err := parseLevelSpec(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "easylog: invalid %s: %v\n", LEVEL_ENV, err)
+			return
+		}
+		if level >= 0 {
+			envLevel.level = level
+		}
+		envLevel.modules = modules
+	}
+}
+
+// Level of packages matching pattern
+type moduleLevel struct {
+	pattern string
+	level   int
+}
+
+// Parse level from name, letter or number, e.g. "debug", "D", "0"
+func ParseLevel(s string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "d", "debug":
+		return D, nil
+	case "i", "info":
+		return I, nil
+	case "w", "warn", "warning":
+		return W, nil
+	case "e", "error":
+		return E, nil
+	case "f", "fatal":
+		return F, nil
+	case "p", "panic":
+		return P, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= D && n <= P {
+		return n, nil
+	}
+	return 0, fmt.Errorf("easylog: invalid level %q", s)
+}
+
+// Parse spec like "fio=debug,github.com/foo/*=warn,*=info"
+// Patterns match full import path or last element of package, "*" or bare level is level of logger
+// Level of logger is -1 if not given
+func parseLevelSpec(spec string) (int, []moduleLevel, error) {
+	level := -1
+	var modules []moduleLevel
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		pattern, name := "*", item
+		if pos := strings.LastIndexByte(item, '='); pos != -1 {
+			pattern, name = strings.TrimSpace(item[:pos]), item[pos+1:]
+		}
+		lv, err := ParseLevel(name)
+		if err != nil {
+			return -1, nil, err
+		}
+		if pattern == "*" {
+			level = lv
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return -1, nil, fmt.Errorf("easylog: invalid pattern %q", pattern)
+		}
+		modules = append(modules, moduleLevel{pattern, lv})
+	}
+	return level, modules, nil
+}
+
+// Set levels by spec like "fio=debug,*=info", module levels not in spec are removed
+func (l *EasyLog) SetLevelSpec(spec string) error {
+	level, modules, err := parseLevelSpec(spec)
+	if err != nil {
+		return err
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if level >= 0 {
+		l.level = level
+	}
+	l.setModules(modules)
+	return nil
+}
+
+// Get levels as spec, e.g. "fio=debug,*=info"
+func (l *EasyLog) LevelSpec() string {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	var items []string
+	for _, m := range l.modules {
+		items = append(items, m.pattern+"="+LevelName(m.level))
+	}
+	items = append(items, "*="+LevelName(l.level))
+	return strings.Join(items, ",")
+}
+
+// Must be called with mtx held
+func (l *EasyLog) setModules(modules []moduleLevel) {
+	l.modules = modules
+	l.pcModules = nil
+	if len(modules) > 0 {
+		l.pcModules = make(map[uintptr]int)
+	}
+}
+
+// Lowest level allowed by logger or any module
+func (l *EasyLog) minLevel() int {
+	level := l.level
+	for _, m := range l.modules {
+		if m.level < level {
+			level = m.level
+		}
+	}
+	return level
+}
+
+// Whether level is enabled for the caller at degree, must be called with mtx held
+func (l *EasyLog) allow(level int, degree int) bool {
+	if len(l.modules) == 0 {
+		return level >= l.level
+	}
+	if level < l.minLevel() {
+		return false
+	}
+	pc, _, _, _ := runtime.Caller(degree + 1)
+	return level >= l.levelOf(pc)
+}
+
+// Whether level is enabled for caller pc, must be called with mtx held
+func (l *EasyLog) allowPC(level int, pc uintptr) bool {
+	if len(l.modules) == 0 || pc == 0 {
+		return level >= l.level
+	}
+	return level >= l.minLevel() && level >= l.levelOf(pc)
+}
+
+// Level of package which pc belongs to, index of matched module is cached by pc
+func (l *EasyLog) levelOf(pc uintptr) int {
+	idx, ok := l.pcModules[pc]
+	if !ok {
+		idx = -1
+		_, pkg := funcName(pc)
+		for i, m := range l.modules {
+			if matchPackage(m.pattern, pkg) {
+				idx = i
+				break
+			}
+		}
+		l.pcModules[pc] = idx
+	}
+	if idx < 0 {
+		return l.level
+	}
+	return l.modules[idx].level
+}
+
+func matchPackage(pattern string, pkg string) bool {
+	if pattern == pkg || pattern == path.Base(pkg) {
+		return true
+	}
+	if ok, _ := path.Match(pattern, pkg); ok {
+		return true
+	}
+	ok, _ := path.Match(pattern, path.Base(pkg))
+	return ok
+}
+
+// Set levels of global logger by spec
+func SetLevelSpec(spec string) error {
+	return elog.SetLevelSpec(spec)
+}
+
+// Named loggers for LevelHandler
+var registry struct {
+	mtx     sync.Mutex
+	loggers map[string]*EasyLog
+}
+
+// Register logger by name, so its level can be changed by LevelHandler
+// The global logger is registered as "default"
+func Register(name string, l *EasyLog) {
+	registry.mtx.Lock()
+	defer registry.mtx.Unlock()
+	if registry.loggers == nil {
+		registry.loggers = make(map[string]*EasyLog)
+	}
+	registry.loggers[name] = l
+}
+
+// Get registered logger by name, nil if not found
+func Lookup(name string) *EasyLog {
+	registry.mtx.Lock()
+	defer registry.mtx.Unlock()
+	return registry.loggers[name]
+}
+
+// Handler reporting and changing levels of registered loggers
+//
+//	GET              report spec of all loggers as json, or one by ?logger=name
+//	PUT or POST      change by form values, logger=name (default "default") with
+//	                 level=debug or spec=fio=debug,*=info
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(serveLevel)
+}
+
+func serveLevel(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	name := r.Form.Get("logger")
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		if name == "" {
+			name = "default"
+		}
+		l := Lookup(name)
+		if l == nil {
+			http.Error(w, "easylog: unknown logger "+name, http.StatusNotFound)
+			return
+		}
+		var err error
+		if spec := r.Form.Get("spec"); spec != "" {
+			err = l.SetLevelSpec(spec)
+		} else if level := r.Form.Get("level"); level != "" {
+			var lv int
+			if lv, err = ParseLevel(level); err == nil {
+				l.SetLevel(lv)
+			}
+		} else {
+			err = fmt.Errorf("easylog: level or spec is required")
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	registry.mtx.Lock()
+	names := make([]string, 0, len(registry.loggers))
+	for n := range registry.loggers {
+		if name == "" || n == name {
+			names = append(names, n)
+		}
+	}
+	loggers := make([]*EasyLog, len(names))
+	sort.Strings(names)
+	for i, n := range names {
+		loggers[i] = registry.loggers[n]
+	}
+	registry.mtx.Unlock()
+
+	if name != "" && len(names) == 0 {
+		http.Error(w, "easylog: unknown logger "+name, http.StatusNotFound)
+		return
+	}
+	specs := make(map[string]string, len(names))
+	for i, n := range names {
+		specs[n] = loggers[i].LevelSpec()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(specs)
+}
+
+// Change level of global logger on SIGUSR1 and SIGUSR2
+func HandleLevelSignals() (stop func()) {
+	return elog.HandleLevelSignals()
+}
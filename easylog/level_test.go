@@ -0,0 +1,99 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	for s, want := range map[string]int{"debug": D, "I": I, "warning": W, "3": E, "Fatal": F, "p": P} {
+		if lv, err := ParseLevel(s); err != nil || lv != want {
+			t.Error(s, lv, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Fail()
+	}
+
+	level, modules, err := parseLevelSpec("fio=debug, github.com/x/*=error ,*=warn")
+	if err != nil || level != W || len(modules) != 2 || modules[1].pattern != "github.com/x/*" || modules[1].level != E {
+		t.Error(level, modules, err)
+	}
+	if level, _, _ := parseLevelSpec("fio=debug"); level != -1 {
+		t.Error(level)
+	}
+	if _, _, err := parseLevelSpec("fio=loud"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestLevelSpec(t *testing.T) {
+	var out bytes.Buffer
+	log := NewWriter(&out, false)
+
+	log.SetLevelSpec("fio=debug,*=warn")
+	log.Info("filtered")
+	log.Debug("filtered")
+	if out.Len() != 0 || log.GetLevel() != W {
+		t.Error(out.String())
+	}
+
+	log.SetLevelSpec("easylog=debug,*=error")
+	log.Debug("debug")
+	log.Info("info")
+	if strings.Count(out.String(), "\n") != 2 {
+		t.Error(out.String())
+	}
+	if log.LevelSpec() != "easylog=debug,*=error" {
+		t.Error(log.LevelSpec())
+	}
+
+	out.Reset()
+	log.SetLevelSpec("github.com/alexloser/goaux/*=fatal,*=debug")
+	log.Error("filtered")
+	if out.Len() != 0 {
+		t.Error(out.String())
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	var out bytes.Buffer
+	Register("handler", NewWriter(&out, false))
+	server := httptest.NewServer(LevelHandler())
+	defer server.Close()
+
+	resp, err := http.PostForm(server.URL, url.Values{"logger": {"handler"}, "spec": {"fio=debug,*=error"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var specs map[string]string
+	json.NewDecoder(resp.Body).Decode(&specs)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || specs["handler"] != "fio=debug,*=error" {
+		t.Error(resp.Status, specs)
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"?logger=handler&level=warn", nil)
+	resp, _ = http.DefaultClient.Do(req)
+	resp.Body.Close()
+	if Lookup("handler").LevelSpec() != "fio=debug,*=warn" {
+		t.Error(Lookup("handler").LevelSpec())
+	}
+
+	resp, _ = http.Get(server.URL + "?logger=nobody")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Error(resp.Status)
+	}
+	resp, _ = http.PostForm(server.URL, url.Values{"logger": {"handler"}, "level": {"loud"}})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Error(resp.Status)
+	}
+}
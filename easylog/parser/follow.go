@@ -0,0 +1,73 @@
+// Parse and filter log files written by easylog
+package parser
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexloser/goaux/fio"
+)
+
+// Default polling interval of Follow
+const FOLLOW_INTERVAL = 250 * time.Millisecond
+
+// Read entries appended to file at path like tail -F, until ctx is done or fn returns an error
+// Entries already in file are skipped unless fromStart is set
+// Rotation and truncation are handled by fio.Tail
+// An entry is passed to fn when the next one begins, or no more lines come in one interval
+func Follow(ctx context.Context, path string, fromStart bool, interval time.Duration, fn func(*Entry) error) error {
+	if interval <= 0 {
+		interval = FOLLOW_INTERVAL
+	}
+	opt := fio.TailOptions{From: fio.TailEnd, Interval: interval}
+	if fromStart {
+		opt.From = fio.TailStart
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	lines, errc := fio.TailChan(ctx, path, opt)
+	// stop tailing and wait for its result
+	stop := func() error {
+		cancel()
+		for range lines {
+		}
+		return <-errc
+	}
+
+	var asm assembler
+	idle := time.NewTimer(interval)
+	defer idle.Stop()
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				if e := asm.flush(); e != nil {
+					fn(e)
+				}
+				return <-errc
+			}
+			if e := asm.feed(line.Text); e != nil {
+				if err := fn(e); err != nil {
+					stop()
+					return err
+				}
+			}
+			if !idle.Stop() {
+				select {
+				case <-idle.C:
+				default:
+				}
+			}
+			idle.Reset(interval)
+		case <-idle.C:
+			// nothing new in one interval, the pending entry must be complete
+			if e := asm.flush(); e != nil {
+				if err := fn(e); err != nil {
+					stop()
+					return err
+				}
+			}
+			idle.Reset(interval)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Parse and filter log files written by easylog
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, []byte("[2019-04-12 18:01:29.244 I] 1 main:1 old\n"), 0644)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	entries := make(chan *Entry, 16)
+	done := make(chan error)
+	go func() {
+		done <- Follow(ctx, path, false, 10*time.Millisecond, func(e *Entry) error {
+			entries <- e
+			return nil
+		})
+	}()
+	next := func(want string) {
+		select {
+		case e := <-entries:
+			if e.Msg != want {
+				t.Errorf("%q != %q", e.Msg, want)
+			}
+		case <-ctx.Done():
+			t.Fatal("timeout waiting", want)
+		}
+	}
+	appendLine := func(line string) {
+		file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+		file.WriteString(line)
+		file.Close()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	appendLine("[2019-04-12 18:01:30.000 I] 1 main:2 one\ncontinued\n")
+	next("one\ncontinued")
+	appendLine("[2019-04-12 18:01:30.000 I] 1 main:3 tw")
+	time.Sleep(50 * time.Millisecond)
+	appendLine("o\n")
+	next("two")
+
+	// rotated, the unfinished last line of old file is not lost
+	appendLine("[2019-04-12 18:01:30.500 I] 1 main:3 unfinished")
+	os.Rename(path, path+".1")
+	appendLine("[2019-04-12 18:01:31.000 I] 1 main:4 three\n")
+	next("unfinished")
+	next("three")
+
+	// truncated
+	os.WriteFile(path, nil, 0644)
+	time.Sleep(50 * time.Millisecond)
+	appendLine("[2019-04-12 18:01:32.000 I] 1 main:5 four\n")
+	next("four")
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,310 @@
+// Parse and filter log files written by easylog
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexloser/goaux/easylog"
+)
+
+// Level of lines which are not written by easylog
+const UNKNOWN = -1
+
+// One parsed record, a message of several lines is joined by '\n'
+type Entry struct {
+	Time   time.Time
+	Level  int // easylog.D to easylog.P, or UNKNOWN
+	Pid    int
+	File   string // "main" of text format or "main.go" of JSON format
+	Line   int
+	Msg    string                 // text after prefix, including key=value fields of text format
+	Fields map[string]interface{} // other keys of JSON format
+	Raw    string                 // original lines without the last linebreak
+}
+
+// Call site of entry as "file:line"
+func (e *Entry) Site() string {
+	return e.File + ":" + strconv.Itoa(e.Line)
+}
+
+// Parse one line of the default text format or JSON format
+func ParseLine(line string) (*Entry, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, "{") {
+		return ParseJSON(line)
+	}
+	return ParseText(line)
+}
+
+var errFormat = errors.New("parser: not an easylog line")
+
+// Parse line like "[2019-04-12 18:01:29.244 I] 6460 main:62 msg key=value"
+func ParseText(line string) (*Entry, error) {
+	n := len(easylog.DEFAULT_TIME_FORMAT)
+	if len(line) < n+4 || line[0] != '[' || line[n+1] != ' ' || line[n+3] != ']' {
+		return nil, errFormat
+	}
+	t, err := time.ParseInLocation(easylog.DEFAULT_TIME_FORMAT, line[1:n+1], time.Local)
+	if err != nil {
+		return nil, errFormat
+	}
+	level, err := easylog.ParseLevel(line[n+2 : n+3])
+	if err != nil {
+		return nil, errFormat
+	}
+	e := &Entry{Time: t, Level: level, Raw: line}
+
+	rest := strings.TrimPrefix(line[n+4:], " ")
+	pid, rest, _ := strings.Cut(rest, " ")
+	if e.Pid, err = strconv.Atoi(pid); err != nil {
+		return nil, errFormat
+	}
+	site, rest, _ := strings.Cut(rest, " ")
+	pos := strings.LastIndexByte(site, ':')
+	if pos < 0 {
+		return nil, errFormat
+	}
+	if e.Line, err = strconv.Atoi(site[pos+1:]); err != nil {
+		return nil, errFormat
+	}
+	e.File, e.Msg = site[:pos], rest
+	return e, nil
+}
+
+// Parse one object written by easylog.JSONEncoder
+func ParseJSON(line string) (*Entry, error) {
+	var m map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	e := &Entry{Level: UNKNOWN, Raw: line, Fields: m}
+	if s, ok := m["time"].(string); ok {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, err
+		}
+		e.Time = t
+		delete(m, "time")
+	}
+	if s, ok := m["level"].(string); ok {
+		level, err := easylog.ParseLevel(s)
+		if err != nil {
+			return nil, err
+		}
+		e.Level = level
+		delete(m, "level")
+	}
+	if n, ok := m["pid"].(json.Number); ok {
+		pid, err := strconv.Atoi(n.String())
+		if err != nil {
+			return nil, err
+		}
+		e.Pid = pid
+		delete(m, "pid")
+	}
+	if s, ok := m["caller"].(string); ok {
+		if pos := strings.LastIndexByte(s, ':'); pos >= 0 {
+			e.File = s[:pos]
+			e.Line, _ = strconv.Atoi(s[pos+1:])
+		}
+		delete(m, "caller")
+	}
+	if s, ok := m["msg"].(string); ok {
+		e.Msg = s
+		delete(m, "msg")
+	}
+	return e, nil
+}
+
+// Collect lines into entries, lines not starting a record belong to the previous one
+type assembler struct {
+	pending *Entry
+}
+
+// Feed one line without linebreak, return the previous entry if the line starts a new one
+func (a *assembler) feed(line string) *Entry {
+	e, err := ParseLine(line)
+	if err != nil {
+		if a.pending != nil {
+			a.pending.Msg += "\n" + line
+			a.pending.Raw += "\n" + line
+			return nil
+		}
+		e = &Entry{Level: UNKNOWN, Msg: line, Raw: line}
+	}
+	prev := a.pending
+	a.pending = e
+	return prev
+}
+
+// Take the pending entry
+func (a *assembler) flush() *Entry {
+	e := a.pending
+	a.pending = nil
+	return e
+}
+
+// Read entries from a stream, like bufio.Scanner
+type Scanner struct {
+	r     *bufio.Reader
+	asm   assembler
+	entry *Entry
+	err   error
+	eof   bool
+}
+
+// Create scanner on r, lines of any length are supported
+func NewScanner(r io.Reader) *Scanner {
+	return &Scanner{r: bufio.NewReader(r)}
+}
+
+// Advance to the next entry, false at the end or on error
+func (s *Scanner) Scan() bool {
+	for !s.eof {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			s.eof = true
+			if err != io.EOF {
+				s.err = err
+			}
+			if line == "" {
+				break
+			}
+		}
+		if e := s.asm.feed(strings.TrimRight(line, "\r\n")); e != nil {
+			s.entry = e
+			return true
+		}
+	}
+	s.entry = s.asm.flush()
+	return s.entry != nil
+}
+
+// Current entry
+func (s *Scanner) Entry() *Entry {
+	return s.entry
+}
+
+// First error except io.EOF
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// Conditions of entries, zero values match all entries of easylog, set MinLevel to UNKNOWN
+// to include other lines too, e.g. panic output of runtime
+type Filter struct {
+	MinLevel int            // entries below are excluded, UNKNOWN to include other lines
+	MaxLevel int            // entries above are excluded if it's not 0
+	Since    time.Time      // entries before are excluded
+	Until    time.Time      // entries at or after are excluded
+	Pids     []int          // only these processes
+	File     string         // path.Match pattern of file, with or without ".go", or "file:line"
+	Regexp   *regexp.Regexp // matched against Msg
+}
+
+// Whether entry meets all conditions
+func (f *Filter) Match(e *Entry) bool {
+	if e.Level < f.MinLevel || (f.MaxLevel != 0 && e.Level > f.MaxLevel) {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Time.Before(f.Until) {
+		return false
+	}
+	if len(f.Pids) > 0 {
+		found := false
+		for _, pid := range f.Pids {
+			found = found || pid == e.Pid
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.File != "" && !matchFile(f.File, e) {
+		return false
+	}
+	return f.Regexp == nil || f.Regexp.MatchString(e.Msg)
+}
+
+func matchFile(pattern string, e *Entry) bool {
+	name := e.File
+	if strings.Contains(pattern, ":") {
+		name = e.Site()
+	}
+	if ok, _ := path.Match(pattern, name); ok {
+		return true
+	}
+	ok, _ := path.Match(pattern, strings.Replace(name, ".go", "", 1))
+	return ok
+}
+
+// Counts of entries by level and call site
+type Stats struct {
+	Total  int
+	Levels [easylog.P + 1]int
+	Sites  map[string]int // "file:line" -> count
+}
+
+// Count one entry, lines of UNKNOWN level are only in Total
+func (s *Stats) Add(e *Entry) {
+	s.Total++
+	if e.Level < 0 || e.Level > easylog.P {
+		return
+	}
+	s.Levels[e.Level]++
+	if s.Sites == nil {
+		s.Sites = make(map[string]int)
+	}
+	s.Sites[e.Site()]++
+}
+
+// Count of a call site
+type SiteCount struct {
+	Site  string
+	Count int
+}
+
+// The n most frequent call sites, all if n <= 0
+func (s *Stats) TopSites(n int) []SiteCount {
+	sites := make([]SiteCount, 0, len(s.Sites))
+	for site, count := range s.Sites {
+		sites = append(sites, SiteCount{site, count})
+	}
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].Count != sites[j].Count {
+			return sites[i].Count > sites[j].Count
+		}
+		return sites[i].Site < sites[j].Site
+	})
+	if n > 0 && n < len(sites) {
+		sites = sites[:n]
+	}
+	return sites
+}
+
+// Write counts per level and the n most frequent call sites
+func (s *Stats) Print(w io.Writer, n int) {
+	fmt.Fprintf(w, "total %d\n", s.Total)
+	for level, count := range s.Levels {
+		if count > 0 {
+			fmt.Fprintf(w, "%-6s %d\n", easylog.LevelName(level), count)
+		}
+	}
+	for _, site := range s.TopSites(n) {
+		fmt.Fprintf(w, "%8d  %s\n", site.Count, site.Site)
+	}
+}
@@ -0,0 +1,124 @@
+// Parse and filter log files written by easylog
+package parser
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexloser/goaux/easylog"
+)
+
+func TestParseText(t *testing.T) {
+	e, err := ParseLine("[2019-04-12 18:01:29.244 W] 6460 main:62 disk low free=1G\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2019, 4, 12, 18, 1, 29, 244e6, time.Local)
+	if !e.Time.Equal(want) || e.Level != easylog.W || e.Pid != 6460 || e.Site() != "main:62" || e.Msg != "disk low free=1G" {
+		t.Errorf("%+v", e)
+	}
+	for _, line := range []string{"", "hello", "[2019-04-12 18:01:29.244 X] 1 main:1 m", "[2019-04-12 18:01:29.244 I] x main:1 m", "[2019-04-12 18:01:29.244 I] 1 main m"} {
+		if _, err := ParseLine(line); err == nil {
+			t.Error(line)
+		}
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	e, err := ParseLine(`{"time":"2019-04-12T18:01:29.244+08:00","level":"error","pid":7,"caller":"main.go:9","msg":"failed","code":500}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Level != easylog.E || e.Pid != 7 || e.Site() != "main.go:9" || e.Msg != "failed" || e.Time.Nanosecond() != 244e6 {
+		t.Errorf("%+v", e)
+	}
+	if len(e.Fields) != 1 || e.Fields["code"].(interface{ String() string }).String() != "500" {
+		t.Error(e.Fields)
+	}
+}
+
+// Write logs by easylog and read them back
+func TestScanner(t *testing.T) {
+	var buf bytes.Buffer
+	log := easylog.NewWriter(&buf, false)
+	log.SetLevel(easylog.D)
+	log.Debug("one")
+	log.InfoKV("two\nline2", "stack", "a b")
+	log.Error("three")
+	log.SetEncoder(&easylog.JSONEncoder{})
+	log.Warn("four")
+	buf.WriteString("no linebreak at end")
+
+	var msgs []string
+	var stats Stats
+	s := NewScanner(&buf)
+	for s.Scan() {
+		msgs = append(msgs, s.Entry().Msg)
+		stats.Add(s.Entry())
+	}
+	if s.Err() != nil {
+		t.Fatal(s.Err())
+	}
+	want := []string{"one", "two\nline2 stack=\"a b\"", "three", "four\nno linebreak at end"}
+	if strings.Join(msgs, "|") != strings.Join(want, "|") {
+		t.Errorf("%q", msgs)
+	}
+	if stats.Total != 4 || stats.Levels != [easylog.P + 1]int{1, 1, 1, 1, 0, 0} || len(stats.Sites) != 4 {
+		t.Errorf("%+v", stats)
+	}
+
+	var out bytes.Buffer
+	stats.Print(&out, 1)
+	if lines := strings.Split(out.String(), "\n"); len(lines) != 7 || lines[0] != "total 4" || lines[1] != "debug  1" {
+		t.Errorf("%q", lines)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+	e := &Entry{Time: base, Level: easylog.W, Pid: 10, File: "main", Line: 5, Msg: "disk low"}
+	cases := []struct {
+		f    Filter
+		want bool
+	}{
+		{Filter{}, true},
+		{Filter{MinLevel: easylog.E}, false},
+		{Filter{MaxLevel: easylog.I}, false},
+		{Filter{Since: base}, true},
+		{Filter{Since: base.Add(1)}, false},
+		{Filter{Until: base}, false},
+		{Filter{Pids: []int{1, 10}}, true},
+		{Filter{Pids: []int{1}}, false},
+		{Filter{File: "ma*"}, true},
+		{Filter{File: "main:5"}, true},
+		{Filter{File: "main.go:6"}, false},
+		{Filter{Regexp: regexp.MustCompile(`^disk`)}, true},
+		{Filter{Regexp: regexp.MustCompile(`cpu`)}, false},
+	}
+	for i, c := range cases {
+		if c.f.Match(e) != c.want {
+			t.Error(i, c.f)
+		}
+	}
+	e.File = "main.go"
+	if f := (Filter{File: "main:5"}); !f.Match(e) {
+		t.Error("file of JSON format")
+	}
+	other := &Entry{Level: UNKNOWN, Msg: "panic: runtime error"}
+	if f := (Filter{}); f.Match(other) {
+		t.Error("unknown level matched by default")
+	}
+	if f := (Filter{MinLevel: UNKNOWN}); !f.Match(other) || !f.Match(e) {
+		t.Error("unknown level not matched")
+	}
+}
+
+func TestTopSites(t *testing.T) {
+	stats := Stats{Sites: map[string]int{"a:1": 1, "b:2": 3, "c:3": 3}}
+	if top := stats.TopSites(2); len(top) != 2 || top[0] != (SiteCount{"b:2", 3}) || top[1] != (SiteCount{"c:3", 3}) {
+		t.Error(top)
+	}
+}
@@ -0,0 +1,427 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options of RemoteSink, zero values are defaults
+type RemoteOptions struct {
+	URL           string        // POST batches as JSON lines to this http(s) url
+	Addr          string        // or stream lines to this host:port over TCP if URL is empty
+	Level         int           // minimum level of sink
+	Encoder       Encoder       // JSONEncoder if nil
+	BatchSize     int           // records per request, 100 by default
+	FlushInterval time.Duration // max delay of queued records, 1s by default
+	QueueSize     int           // records kept in memory, 10000 by default
+	MinBackoff    time.Duration // first delay after failure, 100ms by default
+	MaxBackoff    time.Duration // delay doubles up to this, 30s by default
+	SpoolDir      string        // spool unsent records here and replay them later, empty to keep them in memory
+	Client        *http.Client  // http.DefaultClient if nil
+	Timeout       time.Duration // timeout of each HTTP request, dialing and writing TCP, and Sync, 10s by default
+}
+
+// Counters of RemoteSink in records
+type RemoteStats struct {
+	Sent    uint64 // delivered to remote, including replayed from spool
+	Failed  uint64 // failed to deliver, counted on every attempt
+	Spooled uint64 // written into spool directory
+	Dropped uint64 // discarded because the queue is full and no spool directory, or rejected by remote
+}
+
+// Sink shipping records to a collector in batches by a background goroutine
+// Delivery is retried with exponential backoff, and records are spooled on disk while the remote is down
+type RemoteSink struct {
+	opt     RemoteOptions
+	mtx     sync.Mutex // guard buf, queue, seq and closed
+	buf     bytes.Buffer
+	queue   [][]byte
+	seq     int
+	closed  bool
+	conn    net.Conn // used only by loop
+	backoff time.Duration
+	retryAt time.Time
+	kick    chan struct{}
+	flush   chan chan error
+	quit    chan struct{}
+	done    chan struct{}
+	stats   remoteCounters
+}
+
+// Counters of RemoteSink, atomic.Uint64 is aligned on 32-bit platforms
+type remoteCounters struct {
+	sent, failed, spooled, dropped atomic.Uint64
+}
+
+// Extension of spool files, files being written are *.tmp
+const SPOOL_EXT = ".jsonl"
+
+// Create remote sink and start its goroutine, nothing is connected until the first batch
+func NewRemoteSink(opt RemoteOptions) (*RemoteSink, error) {
+	if opt.URL == "" && opt.Addr == "" {
+		return nil, errors.New("easylog: URL or Addr is required")
+	}
+	if opt.Encoder == nil {
+		opt.Encoder = &JSONEncoder{}
+	}
+	if opt.BatchSize <= 0 {
+		opt.BatchSize = 100
+	}
+	if opt.FlushInterval <= 0 {
+		opt.FlushInterval = time.Second
+	}
+	if opt.QueueSize < opt.BatchSize {
+		opt.QueueSize = 10000
+		if opt.QueueSize < opt.BatchSize {
+			opt.QueueSize = opt.BatchSize
+		}
+	}
+	if opt.MinBackoff <= 0 {
+		opt.MinBackoff = 100 * time.Millisecond
+	}
+	if opt.MaxBackoff < opt.MinBackoff {
+		opt.MaxBackoff = 30 * time.Second
+		if opt.MaxBackoff < opt.MinBackoff {
+			opt.MaxBackoff = opt.MinBackoff
+		}
+	}
+	if opt.Client == nil {
+		opt.Client = http.DefaultClient
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 10 * time.Second
+	}
+	if opt.SpoolDir != "" {
+		if err := os.MkdirAll(opt.SpoolDir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	s := &RemoteSink{
+		opt:   opt,
+		kick:  make(chan struct{}, 1),
+		flush: make(chan chan error),
+		quit:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go s.loop()
+	return s, nil
+}
+
+func (s *RemoteSink) Level() int {
+	return s.opt.Level
+}
+
+// Encode r into queue, a full queue is moved into spool or its oldest record is dropped
+func (s *RemoteSink) Emit(r *Record) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.closed {
+		return os.ErrClosed
+	}
+	s.buf.Reset()
+	s.opt.Encoder.Encode(&s.buf, r)
+	if len(s.queue) >= s.opt.QueueSize {
+		if s.opt.SpoolDir == "" || s.spool(s.queue) != nil {
+			s.stats.dropped.Add(1)
+			s.queue = s.queue[1:]
+		} else {
+			s.queue = nil
+		}
+	}
+	s.queue = append(s.queue, append([]byte(nil), s.buf.Bytes()...))
+	if len(s.queue) >= s.opt.BatchSize {
+		select {
+		case s.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Get counters
+func (s *RemoteSink) Stats() RemoteStats {
+	return RemoteStats{
+		Sent:    s.stats.sent.Load(),
+		Failed:  s.stats.failed.Load(),
+		Spooled: s.stats.spooled.Load(),
+		Dropped: s.stats.dropped.Load(),
+	}
+}
+
+// Try to deliver all queued and spooled records now regardless of backoff, return the error of delivery
+// It waits at most Timeout since EasyLog.Flush holds the logger, os.ErrDeadlineExceeded is returned then
+func (s *RemoteSink) Sync() error {
+	timer := time.NewTimer(s.opt.Timeout)
+	defer timer.Stop()
+	reply := make(chan error, 1)
+	select {
+	case s.flush <- reply:
+	case <-s.done:
+		return os.ErrClosed
+	case <-timer.C:
+		return os.ErrDeadlineExceeded
+	}
+	select {
+	case err := <-reply:
+		return err
+	case <-timer.C:
+		return os.ErrDeadlineExceeded
+	}
+}
+
+// Deliver or spool remaining records and stop the goroutine, they are lost without SpoolDir if remote is down
+func (s *RemoteSink) Close() error {
+	s.mtx.Lock()
+	if s.closed {
+		s.mtx.Unlock()
+		return os.ErrClosed
+	}
+	s.closed = true
+	s.mtx.Unlock()
+	close(s.quit)
+	<-s.done
+	return nil
+}
+
+func (s *RemoteSink) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opt.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.kick:
+			s.ship(false)
+		case <-ticker.C:
+			s.ship(false)
+		case reply := <-s.flush:
+			reply <- s.ship(true)
+		case <-s.quit:
+			s.ship(true)
+			if s.conn != nil {
+				s.conn.Close()
+			}
+			return
+		}
+	}
+}
+
+// Replay spool and send queue in batches, stop at the first failure and spool the rest
+// If force is set, backoff is ignored
+func (s *RemoteSink) ship(force bool) error {
+	if !force && time.Now().Before(s.retryAt) {
+		return nil
+	}
+	err := s.replay()
+	for err == nil {
+		s.mtx.Lock()
+		n := len(s.queue)
+		if n > s.opt.BatchSize {
+			n = s.opt.BatchSize
+		}
+		batch := s.queue[:n:n]
+		s.queue = s.queue[n:]
+		s.mtx.Unlock()
+		if n == 0 {
+			break
+		}
+		if err = s.send(bytes.Join(batch, nil)); isRejected(err) {
+			s.stats.dropped.Add(uint64(n))
+			err = nil
+			continue
+		} else if err != nil {
+			s.stats.failed.Add(uint64(n))
+			s.requeue(batch)
+			break
+		}
+		s.stats.sent.Add(uint64(n))
+	}
+	if err == nil {
+		s.backoff = 0
+		s.retryAt = time.Time{}
+		return nil
+	}
+
+	s.backoff *= 2
+	if s.backoff < s.opt.MinBackoff {
+		s.backoff = s.opt.MinBackoff
+	}
+	if s.backoff > s.opt.MaxBackoff {
+		s.backoff = s.opt.MaxBackoff
+	}
+	s.retryAt = time.Now().Add(s.backoff)
+
+	// remote is down, move everything to disk in order
+	if s.opt.SpoolDir != "" {
+		s.mtx.Lock()
+		if len(s.queue) > 0 && s.spool(s.queue) == nil {
+			s.queue = nil
+		}
+		s.mtx.Unlock()
+	}
+	return err
+}
+
+// Put batch back to the front of queue, oldest records are dropped if it's full
+func (s *RemoteSink) requeue(batch [][]byte) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.queue = append(batch, s.queue...)
+	if over := len(s.queue) - s.opt.QueueSize; over > 0 && s.opt.SpoolDir == "" {
+		s.stats.dropped.Add(uint64(over))
+		s.queue = s.queue[over:]
+	}
+}
+
+// Write records into a new spool file, must be called with mtx held
+func (s *RemoteSink) spool(records [][]byte) error {
+	s.seq++
+	name := filepath.Join(s.opt.SpoolDir, fmt.Sprintf("%020d-%06d", time.Now().UnixNano(), s.seq%1000000))
+	file, err := os.OpenFile(name+".tmp", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		if _, err = file.Write(rec); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if e := file.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(name+".tmp", name+SPOOL_EXT)
+	}
+	if err != nil {
+		os.Remove(name + ".tmp")
+		return err
+	}
+	s.stats.spooled.Add(uint64(len(records)))
+	return nil
+}
+
+// Send spool files from the oldest in batches, every file is removed once it's delivered
+// On failure the delivered part of file is cut off, batches rejected by remote are dropped
+func (s *RemoteSink) replay() error {
+	if s.opt.SpoolDir == "" {
+		return nil
+	}
+	names, err := filepath.Glob(filepath.Join(s.opt.SpoolDir, "*"+SPOOL_EXT))
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		for rest := data; len(rest) > 0; {
+			end, n := 0, 0
+			for ; n < s.opt.BatchSize && end < len(rest); n++ {
+				if i := bytes.IndexByte(rest[end:], '\n'); i >= 0 {
+					end += i + 1
+				} else {
+					end = len(rest)
+				}
+			}
+			if err := s.send(rest[:end]); isRejected(err) {
+				s.stats.dropped.Add(uint64(n))
+			} else if err != nil {
+				s.stats.failed.Add(uint64(n))
+				if len(rest) < len(data) {
+					s.cutSpool(name, rest)
+				}
+				return err
+			} else {
+				s.stats.sent.Add(uint64(n))
+			}
+			rest = rest[end:]
+		}
+		os.Remove(name)
+	}
+	return nil
+}
+
+// Replace spool file by its undelivered records
+func (s *RemoteSink) cutSpool(name string, rest []byte) error {
+	tmp := strings.TrimSuffix(name, SPOOL_EXT) + ".tmp"
+	if err := os.WriteFile(tmp, rest, 0644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// Error of remote refusing a batch for good, e.g. 400 or 413, it's dropped instead of retried
+type rejectedError struct {
+	msg string
+}
+
+func (e *rejectedError) Error() string {
+	return e.msg
+}
+
+func isRejected(err error) bool {
+	var r *rejectedError
+	return errors.As(err, &r)
+}
+
+// Deliver lines in one request or write
+func (s *RemoteSink) send(data []byte) error {
+	if s.opt.URL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), s.opt.Timeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.opt.URL, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		resp, err := s.opt.Client.Do(req)
+		if err != nil {
+			return err
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			msg := fmt.Sprintf("easylog: %s returned %s", s.opt.URL, resp.Status)
+			// other 4xx won't succeed by retrying
+			if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+				resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+				return &rejectedError{msg}
+			}
+			return errors.New(msg)
+		}
+		return nil
+	}
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout("tcp", s.opt.Addr, s.opt.Timeout)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(s.opt.Timeout))
+	if _, err := s.conn.Write(data); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Collector accepting JSON lines, it fails with 503 while down is set
+type collector struct {
+	mtx      sync.Mutex
+	down     bool
+	msgs     []string
+	requests int
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.down {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+		return
+	}
+	c.requests++
+	data, _ := io.ReadAll(r.Body)
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.msgs = append(c.msgs, rec["msg"].(string))
+	}
+}
+
+func (c *collector) setDown(down bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.down = down
+}
+
+func (c *collector) received() string {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return strings.Join(c.msgs, ",")
+}
+
+func TestRemoteSinkHTTP(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	sink, err := NewRemoteSink(RemoteOptions{URL: srv.URL, BatchSize: 2, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := NewSinks(false, sink)
+	for _, msg := range []string{"a", "b", "c", "d", "e"} {
+		log.Info(msg)
+	}
+	if err := sink.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.received(); got != "a,b,c,d,e" || c.requests != 3 {
+		t.Error(got, c.requests)
+	}
+	if st := sink.Stats(); st != (RemoteStats{Sent: 5}) {
+		t.Error(st)
+	}
+	log.Close()
+	if sink.Emit(&Record{}) != os.ErrClosed || sink.Sync() != os.ErrClosed {
+		t.Error("used after close")
+	}
+}
+
+func TestRemoteSinkHang(t *testing.T) {
+	// collector accepting requests and never answering
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	sink, err := NewRemoteSink(RemoteOptions{URL: srv.URL, FlushInterval: time.Hour, Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := NewSinks(false, sink)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		log.Info("a")
+		log.Flush()
+		log.Info("b")
+		log.Close()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logger blocked by remote")
+	}
+	if st := sink.Stats(); st.Sent != 0 || st.Failed == 0 {
+		t.Error(st)
+	}
+}
+
+func TestRemoteSinkSpool(t *testing.T) {
+	c := &collector{down: true}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	dir := filepath.Join(t.TempDir(), "spool")
+
+	sink, err := NewRemoteSink(RemoteOptions{URL: srv.URL, SpoolDir: dir, FlushInterval: time.Hour, MinBackoff: time.Second, MaxBackoff: 3 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := NewSinks(false, sink)
+	log.Info("a")
+	log.Info("b")
+	if sink.Sync() == nil {
+		t.Fatal("remote is down")
+	}
+	log.Info("c")
+	if sink.Sync() == nil {
+		t.Fatal("remote is down")
+	}
+	if sink.backoff != 2*time.Second {
+		t.Error(sink.backoff)
+	}
+	sink.Sync()
+	if sink.backoff != 3*time.Second {
+		t.Error(sink.backoff)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+SPOOL_EXT))
+	if len(files) != 2 {
+		t.Error(files)
+	}
+	// replaying stops at the first spool file, which is counted as failed every time
+	if st := sink.Stats(); st != (RemoteStats{Failed: 2 + 2 + 2, Spooled: 3}) {
+		t.Errorf("%+v", st)
+	}
+
+	c.setDown(false)
+	log.Info("d")
+	log.Close()
+	if got := c.received(); got != "a,b,c,d" {
+		t.Error(got)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Error(files)
+	}
+	if st := sink.Stats(); st.Sent != 4 || st.Spooled != 3 {
+		t.Errorf("%+v", st)
+	}
+}
+
+func TestRemoteSinkReplayAtStart(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "1"+SPOOL_EXT), []byte(`{"msg":"old"}`+"\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "2.tmp"), []byte(`{"msg":"partial"}`+"\n"), 0644)
+
+	sink, _ := NewRemoteSink(RemoteOptions{URL: srv.URL, SpoolDir: dir})
+	log := NewSinks(false, sink)
+	log.Info("new")
+	log.Close()
+	if got := c.received(); got != "old,new" {
+		t.Error(got)
+	}
+}
+
+func TestRemoteSinkReplayBatches(t *testing.T) {
+	var mtx sync.Mutex
+	var got []string
+	failed := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		data, _ := io.ReadAll(r.Body)
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		switch {
+		case len(lines) > 2:
+			http.Error(w, "too large", http.StatusRequestEntityTooLarge)
+		case strings.Contains(string(data), "bad"):
+			http.Error(w, "bad", http.StatusBadRequest)
+		case strings.Contains(string(data), `"c"`) && !failed:
+			failed = true
+			http.Error(w, "down", http.StatusServiceUnavailable)
+		default:
+			for _, line := range lines {
+				var rec map[string]interface{}
+				json.Unmarshal([]byte(line), &rec)
+				got = append(got, rec["msg"].(string))
+			}
+		}
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+	var spool strings.Builder
+	for _, msg := range []string{"a", "bad", "c", "d", "e"} {
+		spool.WriteString(`{"msg":"` + msg + `"}` + "\n")
+	}
+	os.WriteFile(filepath.Join(dir, "1"+SPOOL_EXT), []byte(spool.String()), 0644)
+
+	sink, _ := NewRemoteSink(RemoteOptions{URL: srv.URL, SpoolDir: dir, BatchSize: 2, FlushInterval: time.Hour})
+	defer sink.Close()
+	// the rejected batch is dropped, the delivered part is cut off when remote fails
+	if err := sink.Sync(); err == nil {
+		t.Error("remote is down")
+	}
+	if err := sink.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	mtx.Lock()
+	defer mtx.Unlock()
+	if strings.Join(got, ",") != "c,d,e" {
+		t.Error(got)
+	}
+	if st := sink.Stats(); st != (RemoteStats{Sent: 3, Failed: 2, Dropped: 2}) {
+		t.Error(st)
+	}
+	if names, _ := filepath.Glob(filepath.Join(dir, "*")); len(names) != 0 {
+		t.Error(names)
+	}
+}
+
+func TestRemoteSinkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	sink, err := NewRemoteSink(RemoteOptions{Addr: ln.Addr().String(), Encoder: &LogfmtEncoder{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := NewSinks(false, sink)
+	log.Info("one")
+	log.WarnKV("two", "k", 1)
+	log.Close()
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for _, want := range []string{"msg=one", "msg=two k=1"} {
+		line, err := r.ReadString('\n')
+		if err != nil || !strings.Contains(line, want) {
+			t.Error(line, err)
+		}
+	}
+	if st := sink.Stats(); st.Sent != 2 {
+		t.Errorf("%+v", st)
+	}
+}
@@ -0,0 +1,165 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"time"
+)
+
+// How often FileSink checks whether its path still refers to the opened file
+const REOPEN_CHECK_INTERVAL = time.Second
+
+// Sink writing into a file by path, the path is reopened by Reopen or when the file
+// is moved or removed by tools like logrotate, so records go to the new file
+type FileSink struct {
+	mtx     sync.Mutex
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	level   int
+	enc     Encoder
+	buf     bytes.Buffer
+	checked time.Time
+}
+
+// Open or create file at path for appending, nil enc means TextEncoder
+func NewFileSink(path string, level int, enc Encoder) (*FileSink, error) {
+	if enc == nil {
+		enc = &TextEncoder{}
+	}
+	s := &FileSink{path: path, level: level, enc: enc}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Create an independent logger writing into file at path, see FileSink
+func NewFile(path string, unsafe bool) (*EasyLog, error) {
+	s, err := NewFileSink(path, D, nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewSinks(unsafe, s), nil
+}
+
+// Open path and replace the current file, the old one is kept if failed
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if s.file != nil {
+		s.file.Sync()
+		s.file.Close()
+	}
+	s.file, s.info = file, info
+	s.checked = time.Now()
+	return nil
+}
+
+// Whether path refers to another file or nothing, checked once per REOPEN_CHECK_INTERVAL
+func (s *FileSink) moved(now time.Time) bool {
+	if now.Sub(s.checked) < REOPEN_CHECK_INTERVAL {
+		return false
+	}
+	s.checked = now
+	info, err := os.Stat(s.path)
+	return err != nil || !os.SameFile(info, s.info)
+}
+
+func (s *FileSink) Level() int {
+	return s.level
+}
+
+// Encode r and write it, reopen path first if the file was moved
+func (s *FileSink) Emit(r *Record) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.file == nil {
+		return os.ErrClosed
+	}
+	if s.moved(r.Time) {
+		s.open()
+	}
+	s.buf.Reset()
+	s.enc.Encode(&s.buf, r)
+	_, err := s.file.Write(s.buf.Bytes())
+	return err
+}
+
+// Close current file and open path again
+func (s *FileSink) Reopen() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.file == nil {
+		return os.ErrClosed
+	}
+	return s.open()
+}
+
+// Set format of sink
+func (s *FileSink) SetEncoder(enc Encoder) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.enc = enc
+}
+
+// Get the path of sink
+func (s *FileSink) Path() string {
+	return s.path
+}
+
+func (s *FileSink) Sync() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Sync()
+}
+
+func (s *FileSink) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	s.file.Sync()
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// Reopen every sink which supports it, e.g. FileSink
+// Logging is blocked while swapping, so no record is lost or written into the old file
+func (l *EasyLog) Reopen() error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	var err error
+	for _, s := range l.sinks {
+		if r, ok := s.(interface{ Reopen() error }); ok {
+			if e := r.Reopen(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+// Reopen sinks of global logger
+func Reopen() error {
+	return elog.Reopen()
+}
+
+// Reopen sinks of global logger on SIGHUP
+func ReopenOnSignal() (stop func()) {
+	return elog.ReopenOnSignal()
+}
@@ -0,0 +1,75 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFileSinkReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := NewFile(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	log.Info("one")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	log.Info("two")
+	if err := log.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	log.Info("three")
+	log.SetEncoder(&JSONEncoder{})
+	log.Info("four")
+
+	old, cur := readFile(t, path+".1"), readFile(t, path)
+	if !strings.Contains(old, "one") || !strings.Contains(old, "two") || strings.Contains(old, "three") {
+		t.Error(old)
+	}
+	if !strings.Contains(cur, " three\n") || !strings.HasSuffix(cur, `"msg":"four"}`+"\n") || strings.Count(cur, "\n") != 2 {
+		t.Error(cur)
+	}
+}
+
+func TestFileSinkMoved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	sink, err := NewFileSink(path, D, &LogfmtEncoder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	log := NewSinks(false, sink)
+
+	log.Info("one")
+	os.Rename(path, path+".1")
+	log.Info("two") // not checked within REOPEN_CHECK_INTERVAL
+	sink.checked = sink.checked.Add(-REOPEN_CHECK_INTERVAL)
+	log.Info("three")
+	os.Remove(path)
+	sink.checked = sink.checked.Add(-REOPEN_CHECK_INTERVAL)
+	log.Info("four")
+
+	if old := readFile(t, path+".1"); strings.Count(old, "\n") != 2 || !strings.Contains(old, `msg=two`) {
+		t.Error(old)
+	}
+	if cur := readFile(t, path); strings.Count(cur, "\n") != 1 || !strings.Contains(cur, `msg=four`) {
+		t.Error(cur)
+	}
+	if sink.Close(); sink.Emit(&Record{}) != os.ErrClosed {
+		t.Error("emit after close")
+	}
+}
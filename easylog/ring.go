@@ -0,0 +1,72 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"sync"
+)
+
+// Sink keeping the last N records in memory, e.g. for a diagnostics endpoint
+type RingSink struct {
+	mtx     sync.Mutex
+	level   int
+	records []Record
+	head    int
+	count   int
+}
+
+// Create ring sink keeping the last size records of level and above
+func NewRingSink(size int, level int) *RingSink {
+	if size < 1 {
+		size = 1
+	}
+	return &RingSink{level: level, records: make([]Record, size)}
+}
+
+func (s *RingSink) Level() int {
+	return s.level
+}
+
+// Keep a copy of r, the oldest one is overwritten if full
+func (s *RingSink) Emit(r *Record) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	rec := *r
+	rec.Fields = append([]interface{}(nil), r.Fields...)
+	rec.Context = append([]interface{}(nil), r.Context...)
+	if s.count < len(s.records) {
+		s.records[(s.head+s.count)%len(s.records)] = rec
+		s.count++
+	} else {
+		s.records[s.head] = rec
+		s.head = (s.head + 1) % len(s.records)
+	}
+	return nil
+}
+
+// Get kept records, the oldest first
+func (s *RingSink) Records() []Record {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	ret := make([]Record, s.count)
+	for i := range ret {
+		ret[i] = s.records[(s.head+i)%len(s.records)]
+	}
+	return ret
+}
+
+// Number of kept records
+func (s *RingSink) Len() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.count
+}
+
+// Remove all kept records
+func (s *RingSink) Reset() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for i := range s.records {
+		s.records[i] = Record{}
+	}
+	s.head, s.count = 0, 0
+}
@@ -0,0 +1,34 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"testing"
+)
+
+func TestRingSink(t *testing.T) {
+	ring := NewRingSink(3, E)
+	log := NewSinks(false, ring)
+	for i := 0; i < 5; i++ {
+		log.ErrorKV("error", "i", i)
+		log.Info("filtered")
+	}
+	records := ring.Records()
+	if len(records) != 3 || ring.Len() != 3 {
+		t.Fatal(records)
+	}
+	for i, r := range records {
+		if r.Level != E || r.Msg != "error" || r.Fields[1] != i+2 || r.Line != 12 {
+			t.Error(r)
+		}
+	}
+
+	log.RemoveSink(ring)
+	log.Error("removed")
+	if ring.Len() != 3 || len(log.Sinks()) != 0 {
+		t.Fail()
+	}
+	ring.Reset()
+	if len(ring.Records()) != 0 {
+		t.Fail()
+	}
+}
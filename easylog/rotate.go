@@ -0,0 +1,292 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alexloser/goaux/utils"
+)
+
+// Time boundaries for rotation
+const (
+	RotateNever  int = iota // Only rotate by size
+	RotateHourly int = iota // Rotate at the beginning of every hour
+	RotateDaily  int = iota // Rotate at midnight
+)
+
+// Options of RotateWriter, zero value means disabled
+type RotateOptions struct {
+	MaxSize  int64  // Rotate when the active file passes this size in bytes
+	Every    int    // RotateNever, RotateHourly or RotateDaily
+	Pattern  string // utils.Strftime pattern of rotated names, relative to dir of the active file
+	Compress bool   // Gzip rotated segments
+	MaxFiles int    // Keep only the newest N rotated files
+	MaxDays  int    // Remove rotated files older than N days
+}
+
+// Default pattern of rotated file names
+const DEFAULT_ROTATE_PATTERN = "%Y%m%d-%H%M%S"
+
+// A file writer which rolls the log by size or time boundary
+// Each Write is checked and written as a whole, so lines never cross two files
+type RotateWriter struct {
+	mtx     sync.Mutex
+	path    string
+	opt     RotateOptions
+	file    *os.File
+	size    int64
+	opened  time.Time
+	next    time.Time
+	pending sync.WaitGroup
+	bgmtx   sync.Mutex // serialize compressing and cleanup
+}
+
+// Open or create the active log file at path
+func NewRotateWriter(path string, opt RotateOptions) (*RotateWriter, error) {
+	if opt.Pattern == "" {
+		opt.Pattern = filepath.Base(path) + "." + DEFAULT_ROTATE_PATTERN
+	}
+	w := &RotateWriter{path: path, opt: opt}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotateWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	w.opened = time.Now()
+	if w.size > 0 {
+		w.opened = info.ModTime()
+	}
+	w.next = nextBoundary(time.Now(), w.opt.Every)
+	return nil
+}
+
+// Get the next rotating time after t, zero time if never
+func nextBoundary(t time.Time, every int) time.Time {
+	switch every {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+// Write p into active file, rotate first if needed
+func (w *RotateWriter) Write(p []byte) (int, error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if w.file == nil {
+		return 0, os.ErrClosed
+	}
+	if w.shouldRotate(int64(len(p))) {
+		// keep writing into the old file if it could not be renamed
+		if err := w.rotate(); err != nil && w.file == nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *RotateWriter) shouldRotate(n int64) bool {
+	if w.opt.MaxSize > 0 && w.size > 0 && w.size+n > w.opt.MaxSize {
+		return true
+	}
+	return !w.next.IsZero() && !time.Now().Before(w.next)
+}
+
+// Force to rotate the active file
+func (w *RotateWriter) Rotate() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+func (w *RotateWriter) rotate() error {
+	w.file.Sync()
+	if err := w.file.Close(); err != nil {
+		// the handle is unusable anyway, go on with a new one
+		w.file = nil
+		if e := w.open(); e != nil {
+			return e
+		}
+		return err
+	}
+	w.file = nil
+
+	if w.size > 0 {
+		name := w.rotatedName()
+		if err := os.Rename(w.path, name); err != nil {
+			if e := w.open(); e != nil {
+				return e
+			}
+			return err
+		}
+		w.pending.Add(1)
+		go func() {
+			defer w.pending.Done()
+			w.bgmtx.Lock()
+			defer w.bgmtx.Unlock()
+			if w.opt.Compress {
+				gzipFile(name)
+			}
+			w.cleanup()
+		}()
+	}
+
+	return w.open()
+}
+
+// Rotated name is made by the open time of segment, with a sequence suffix on conflict
+func (w *RotateWriter) rotatedName() string {
+	name := utils.Strftime(&w.opened, w.opt.Pattern)
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(filepath.Dir(w.path), name)
+	}
+	exist := func(s string) bool {
+		if _, err := os.Stat(s); err == nil {
+			return true
+		}
+		_, err := os.Stat(s + ".gz")
+		return err == nil
+	}
+	if !exist(name) {
+		return name
+	}
+	for i := 1; ; i++ {
+		if s := fmt.Sprintf("%s.%d", name, i); !exist(s) {
+			return s
+		}
+	}
+}
+
+// Compress the file into file.gz and remove it
+func gzipFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	dst.Close()
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+	src.Close()
+	return os.Remove(name)
+}
+
+type rotatedFile struct {
+	path  string
+	mtime time.Time
+}
+
+// All rotated files, newest first
+func (w *RotateWriter) rotatedFiles() []rotatedFile {
+	pattern := w.opt.Pattern
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(w.path), pattern)
+	}
+	// every directive matches anything
+	var glob strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '%' && i+1 < len(pattern) {
+			glob.WriteByte('*')
+			i++
+		} else {
+			glob.WriteByte(pattern[i])
+		}
+	}
+	glob.WriteByte('*')
+
+	names, _ := filepath.Glob(glob.String())
+	files := make([]rotatedFile, 0, len(names))
+	for _, name := range names {
+		if name == w.path {
+			continue
+		}
+		if info, err := os.Stat(name); err == nil && info.Mode().IsRegular() {
+			files = append(files, rotatedFile{name, info.ModTime()})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].mtime.After(files[j].mtime)
+	})
+	return files
+}
+
+// Remove rotated files beyond MaxFiles or older than MaxDays
+func (w *RotateWriter) cleanup() {
+	if w.opt.MaxFiles <= 0 && w.opt.MaxDays <= 0 {
+		return
+	}
+	deadline := time.Now().AddDate(0, 0, -w.opt.MaxDays)
+	for i, f := range w.rotatedFiles() {
+		if (w.opt.MaxFiles > 0 && i >= w.opt.MaxFiles) ||
+			(w.opt.MaxDays > 0 && f.mtime.Before(deadline)) {
+			os.Remove(f.path)
+		}
+	}
+}
+
+// Sync active file into disk
+func (w *RotateWriter) Sync() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.file == nil {
+		return os.ErrClosed
+	}
+	return w.file.Sync()
+}
+
+// Close active file and wait for compressing
+func (w *RotateWriter) Close() error {
+	w.mtx.Lock()
+	var err error
+	if w.file != nil {
+		w.file.Sync()
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mtx.Unlock()
+	w.pending.Wait()
+	return err
+}
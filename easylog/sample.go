@@ -0,0 +1,140 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"strconv"
+	"time"
+)
+
+// Sampling policy of one level: in every interval, the first N lines of each
+// call site are written, then every Mth after that
+type sampling struct {
+	first      int
+	thereafter int
+	interval   time.Duration
+}
+
+// Call site of a log line
+type siteKey struct {
+	file string
+	line int
+}
+
+// Counter of one call site in current interval
+type siteCount struct {
+	pc         uintptr
+	level      int
+	start      time.Time
+	interval   time.Duration
+	n          int
+	suppressed int
+}
+
+// Sample repeated lines of level by call site, thereafter 0 means drop all after first N
+// At the end of each interval, a summary line "suppressed N similar messages" is written by a timer,
+// or by the next logging call if the logger is unsafe, and by Flush
+// Sampling needs caller lookup, it's disabled by SetCaller(false)
+func (l *EasyLog) SetSampling(level int, first int, thereafter int, interval time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if level < D || level > P {
+		return
+	}
+	if first <= 0 && thereafter <= 0 || interval <= 0 {
+		l.sampling[level] = nil
+	} else {
+		l.sampling[level] = &sampling{first, thereafter, interval}
+	}
+	if l.sites == nil {
+		l.sites = make(map[siteKey]*siteCount)
+	}
+}
+
+// Set sampling of all levels below E, errors and above are always written
+func (l *EasyLog) SetSamplingAll(first int, thereafter int, interval time.Duration) {
+	for level := D; level < E; level++ {
+		l.SetSampling(level, first, thereafter, interval)
+	}
+}
+
+// Whether the line of call site should be written, must be called with mtx held
+func (l *EasyLog) sample(s *sampling, pc uintptr, name string, line int, level int) bool {
+	key := siteKey{name, line}
+	c := l.sites[key]
+	now := time.Now()
+	if c == nil {
+		c = &siteCount{pc: pc, level: level, start: now}
+		l.sites[key] = c
+	} else if now.Sub(c.start) >= s.interval {
+		l.summary(c, name, line)
+		c.start = now
+		c.n = 0
+	}
+	c.interval = s.interval
+
+	c.n++
+	if c.n <= s.first || (s.thereafter > 0 && (c.n-s.first)%s.thereafter == 0) {
+		return true
+	}
+	c.suppressed++
+	l.scheduleSummary(c.start.Add(c.interval))
+	return false
+}
+
+// Arrange writing summaries at due, by timer unless the logger is unsafe, must be called with mtx held
+func (l *EasyLog) scheduleSummary(due time.Time) {
+	if !l.summaryDue.IsZero() && !due.Before(l.summaryDue) {
+		return
+	}
+	l.summaryDue = due
+	if _, unsafe := l.mtx.(*dummy_mutex); unsafe {
+		return
+	}
+	if l.summaryTimer == nil {
+		l.summaryTimer = time.AfterFunc(time.Until(due), l.summaryTick)
+	} else {
+		l.summaryTimer.Reset(time.Until(due))
+	}
+}
+
+func (l *EasyLog) summaryTick() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.dueSummaries(time.Now())
+}
+
+// Write summaries of call sites whose interval has ended and forget them,
+// then schedule the next one, must be called with mtx held
+func (l *EasyLog) dueSummaries(now time.Time) {
+	l.summaryDue = time.Time{}
+	var next time.Time
+	for key, c := range l.sites {
+		due := c.start.Add(c.interval)
+		if !now.Before(due) {
+			l.summary(c, key.file, key.line)
+			delete(l.sites, key)
+		} else if c.suppressed > 0 && (next.IsZero() || due.Before(next)) {
+			next = due
+		}
+	}
+	if !next.IsZero() {
+		l.scheduleSummary(next)
+	}
+}
+
+// Write summary of suppressed lines of call site, must be called with mtx held
+func (l *EasyLog) summary(c *siteCount, name string, line int) {
+	if c.suppressed > 0 {
+		msg := "suppressed " + strconv.Itoa(c.suppressed) + " similar messages"
+		c.suppressed = 0
+		l.emit(nil, c.pc, name, line, c.level, msg, nil, nil)
+	}
+}
+
+// Write summaries of all call sites, must be called with mtx held
+func (l *EasyLog) flushSummaries() {
+	for key, c := range l.sites {
+		l.summary(c, key.file, key.line)
+	}
+	l.summaryDue = time.Time{}
+}
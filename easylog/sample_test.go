@@ -0,0 +1,98 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampling(t *testing.T) {
+	var out bytes.Buffer
+	log := NewWriter(&out, false)
+	log.SetSampling(I, 3, 10, time.Hour)
+
+	for i := 0; i < 100; i++ {
+		log.Info("hot", i)
+	}
+	log.Error("not sampled")
+	// 3 first lines, then 13th, 23rd ... 93rd
+	if n := strings.Count(out.String(), " hot "); n != 3+9 {
+		t.Error(n, out.String())
+	}
+	if !strings.Contains(out.String(), " hot 12\n") || strings.Contains(out.String(), " hot 13\n") {
+		t.Error(out.String())
+	}
+
+	log.Flush()
+	if !strings.HasSuffix(out.String(), "sample_test:17 suppressed 88 similar messages\n") {
+		t.Error(out.String())
+	}
+
+	out.Reset()
+	log.SetSampling(I, 1, 0, 5*time.Millisecond)
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 3; j++ {
+			log.Info("a", i)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	log.mtx.Lock()
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	log.mtx.Unlock()
+	if len(lines) != 4 || !strings.HasSuffix(lines[0], " a 0") ||
+		!strings.HasSuffix(lines[1], "suppressed 2 similar messages") || !strings.HasSuffix(lines[2], " a 1") ||
+		!strings.HasSuffix(lines[3], "suppressed 2 similar messages") {
+		t.Error(out.String())
+	}
+
+	out.Reset()
+	log.SetSampling(I, 0, 0, 0)
+	for i := 0; i < 5; i++ {
+		log.Info("off")
+	}
+	if strings.Count(out.String(), "\n") != 5 {
+		t.Error(out.String())
+	}
+}
+
+func TestSamplingSummaryTimer(t *testing.T) {
+	var out bytes.Buffer
+	log := NewWriter(&out, false)
+	defer log.Close()
+	log.SetSampling(I, 2, 0, 20*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		log.Info("burst", i)
+	}
+
+	// the site stops logging, its summary is written without Flush
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		log.mtx.Lock()
+		s := out.String()
+		log.mtx.Unlock()
+		if strings.HasSuffix(s, " suppressed 8 similar messages\n") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(s)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// unsafe logger has no timer, the next logging call writes the summary
+	out.Reset()
+	unsafe := NewWriter(&out, true)
+	unsafe.SetSampling(I, 1, 0, 5*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		unsafe.Info("burst", i)
+	}
+	time.Sleep(10 * time.Millisecond)
+	unsafe.Error("other")
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[1], "suppressed 2 similar messages") ||
+		!strings.HasSuffix(lines[2], " other") {
+		t.Error(out.String())
+	}
+}
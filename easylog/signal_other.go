@@ -0,0 +1,14 @@
+//go:build !unix
+
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+// No SIGUSR1 and SIGUSR2 on windows or plan9, do nothing
+func (l *EasyLog) HandleLevelSignals() (stop func()) {
+	return func() {}
+}
+
+// No SIGHUP on windows or plan9, call Reopen instead
+func (l *EasyLog) ReopenOnSignal() (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/alexloser/goaux/system"
+)
+
+// Change level on signals, SIGUSR1 for more verbose and SIGUSR2 for less, one level each time
+// Call stop to unregister
+func (l *EasyLog) HandleLevelSignals() (stop func()) {
+	return system.RegistSignalLoop(func(s os.Signal) {
+		l.mtx.Lock()
+		defer l.mtx.Unlock()
+		if s == syscall.SIGUSR1 && l.level > D {
+			l.level--
+		} else if s == syscall.SIGUSR2 && l.level < P {
+			l.level++
+		}
+	}, syscall.SIGUSR1, syscall.SIGUSR2)
+}
+
+// Reopen sinks on every SIGHUP, send it after moving the log file, e.g. postrotate of logrotate
+// Call stop to unregister
+func (l *EasyLog) ReopenOnSignal() (stop func()) {
+	return system.RegistSignalLoop(func(os.Signal) {
+		if err := l.Reopen(); err != nil {
+			fmt.Fprintln(os.Stderr, "easylog: reopen failed:", err)
+		}
+	}, syscall.SIGHUP)
+}
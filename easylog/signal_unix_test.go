@@ -0,0 +1,60 @@
+//go:build unix
+
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestHandleLevelSignals(t *testing.T) {
+	var out bytes.Buffer
+	log := NewWriter(&out, false)
+	log.SetLevel(I)
+	stop := log.HandleLevelSignals()
+	defer stop()
+
+	wait := func(level int) {
+		for i := 0; i < 100 && log.LevelSpec() != "*="+LevelName(level); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if log.LevelSpec() != "*="+LevelName(level) {
+			t.Error(log.LevelSpec())
+		}
+	}
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	wait(D)
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	wait(I)
+}
+
+func TestReopenOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := NewFile(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	stop := log.ReopenOnSignal()
+	defer stop()
+
+	log.Info("one")
+	os.Rename(path, path+".1")
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	log.Info("two")
+	if data, _ := os.ReadFile(path); !strings.HasSuffix(string(data), " two\n") {
+		t.Errorf("%q", data)
+	}
+}
@@ -0,0 +1,92 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// One destination of logger, records below Level are ignored
+// Sync() error and Close() error are called if implemented
+type Sink interface {
+	Level() int
+	Emit(r *Record) error
+}
+
+// Sink encoding records into an io.Writer
+type WriterSink struct {
+	mtx   sync.Mutex
+	w     io.Writer
+	level int
+	enc   Encoder
+	buf   bytes.Buffer
+}
+
+// Create sink on w with minimum level and format, nil enc means TextEncoder
+func NewSink(w io.Writer, level int, enc Encoder) *WriterSink {
+	if enc == nil {
+		enc = &TextEncoder{}
+	}
+	return &WriterSink{w: w, level: level, enc: enc}
+}
+
+func (s *WriterSink) Level() int {
+	return s.level
+}
+
+// Encode r and write it in one Write call
+func (s *WriterSink) Emit(r *Record) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.buf.Reset()
+	s.enc.Encode(&s.buf, r)
+	_, err := s.w.Write(s.buf.Bytes())
+	return err
+}
+
+// Set minimum level of sink
+func (s *WriterSink) SetLevel(level int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.level = level
+}
+
+// Set format of sink
+func (s *WriterSink) SetEncoder(enc Encoder) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.enc = enc
+}
+
+// Get the underlying writer
+func (s *WriterSink) Writer() io.Writer {
+	return s.w
+}
+
+func (s *WriterSink) Sync() error {
+	if syncer, ok := s.w.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// Close the underlying writer, files passed by caller are left open
+func (s *WriterSink) Close() error {
+	s.Sync()
+	return closeWriter(s.w)
+}
+
+func syncSink(s Sink) error {
+	if syncer, ok := s.(interface{ Sync() error }); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+func closeSink(s Sink) error {
+	if c, ok := s.(io.Closer); ok {
+		return c.Close()
+	}
+	return syncSink(s)
+}
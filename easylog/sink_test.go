@@ -0,0 +1,37 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSinks(t *testing.T) {
+	var all, warn, errs bytes.Buffer
+	log := NewSinks(false,
+		NewSink(&all, D, nil),
+		NewSink(&warn, W, &LogfmtEncoder{}))
+	log.AddSink(NewSink(&errs, E, &JSONEncoder{}))
+	log.SetLevel(D)
+
+	log.Debug("debug")
+	log.Warn("warn")
+	log.Error("error")
+
+	if strings.Count(all.String(), "\n") != 3 || !strings.Contains(all.String(), " D] ") {
+		t.Error(all.String())
+	}
+	if strings.Count(warn.String(), "\n") != 2 || !strings.Contains(warn.String(), "level=warn") {
+		t.Error(warn.String())
+	}
+	if strings.Count(errs.String(), "\n") != 1 || !strings.Contains(errs.String(), `"msg":"error"`) {
+		t.Error(errs.String())
+	}
+	if len(log.Sinks()) != 3 {
+		t.Fail()
+	}
+	if err := log.Close(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,167 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// Write a record of caller pc returned by runtime.Callers
+func (l *EasyLog) logPC(ctx context.Context, pc uintptr, level int, msg string, kv []interface{}) {
+	var frame runtime.Frame
+	if pc != 0 {
+		frame, _ = runtime.CallersFrames([]uintptr{pc}).Next()
+	}
+	l.logFrame(ctx, frame, level, msg, kv)
+}
+
+// Write a record of caller frame, level and sampling are checked
+func (l *EasyLog) logFrame(ctx context.Context, frame runtime.Frame, level int, msg string, kv []interface{}) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if !l.allowPC(level, frame.PC) {
+		return
+	}
+	if l.nocaller || frame.PC == 0 {
+		l.emit(nil, 0, "", 0, level, msg, kv, ctx)
+		return
+	}
+	l.writeAt(nil, frame.PC, frame.File, frame.Line, level, msg, kv, ctx)
+}
+
+// Map slog level to easylog level:
+// below Info is D, below Warn is I, below Error is W, up to Error+3 is E and others are F
+func FromSlogLevel(level slog.Level) int {
+	switch {
+	case level < slog.LevelInfo:
+		return D
+	case level < slog.LevelWarn:
+		return I
+	case level < slog.LevelError:
+		return W
+	case level < slog.LevelError+4:
+		return E
+	}
+	return F
+}
+
+// Map easylog level to slog level, F and P are above slog.LevelError
+func ToSlogLevel(level int) slog.Level {
+	switch level {
+	case D:
+		return slog.LevelDebug
+	case I:
+		return slog.LevelInfo
+	case W:
+		return slog.LevelWarn
+	case E:
+		return slog.LevelError
+	}
+	return slog.LevelError + 4
+}
+
+// slog.Handler backed by EasyLog, attributes become fields and groups are prefixes of keys
+// F from slog never exits the process
+type SlogHandler struct {
+	l      *EasyLog
+	attrs  []interface{}
+	prefix string
+}
+
+// Create slog handler, use it by slog.New(easylog.NewSlogHandler(log))
+func NewSlogHandler(l *EasyLog) *SlogHandler {
+	return &SlogHandler{l: l}
+}
+
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	h.l.mtx.Lock()
+	defer h.l.mtx.Unlock()
+	return FromSlogLevel(level) >= h.l.minLevel()
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	kv := make([]interface{}, len(h.attrs), len(h.attrs)+2*r.NumAttrs())
+	copy(kv, h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		kv = appendAttr(kv, h.prefix, a)
+		return true
+	})
+	h.l.logPC(ctx, r.PC, FromSlogLevel(r.Level), r.Message, kv)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	kv := make([]interface{}, len(h.attrs), len(h.attrs)+2*len(attrs))
+	copy(kv, h.attrs)
+	for _, a := range attrs {
+		kv = appendAttr(kv, h.prefix, a)
+	}
+	return &SlogHandler{h.l, kv, h.prefix}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{h.l, h.attrs, h.prefix + name + "."}
+}
+
+// Flatten attribute into key/value pairs, keys of group are joined by '.'
+func appendAttr(kv []interface{}, prefix string, a slog.Attr) []interface{} {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kv
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, g := range a.Value.Group() {
+			kv = appendAttr(kv, prefix, g)
+		}
+		return kv
+	}
+	return append(kv, prefix+a.Key, a.Value.Any())
+}
+
+// io.Writer for log.Logger, every Write is one record of level
+// Use log.SetOutput(easylog.NewStdWriter(log, easylog.I)) to route standard log
+type StdWriter struct {
+	l     *EasyLog
+	level int
+}
+
+// Create writer bridging log.Logger into l
+func NewStdWriter(l *EasyLog, level int) *StdWriter {
+	return &StdWriter{l, level}
+}
+
+// Create log.Logger writing into l at level without prefix and flags
+func NewStdLogger(l *EasyLog, level int) *log.Logger {
+	return log.New(NewStdWriter(l, level), "", 0)
+}
+
+func (w *StdWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	w.l.logFrame(nil, stdCaller(), w.level, msg, nil)
+	return len(p), nil
+}
+
+// Find the first caller out of package log
+func stdCaller() runtime.Frame {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "log.") {
+			return f
+		}
+		if !more {
+			return runtime.Frame{}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var out bytes.Buffer
+	el := NewWriter(&out, false)
+	logger := slog.New(NewSlogHandler(el))
+
+	logger.Debug("filtered")
+	logger.Info("hello", "user", 42, slog.Group("req", "id", "r-1"))
+	if !strings.HasSuffix(out.String(), " I] "+strconv.Itoa(pid)+" slog_test:21 hello user=42 req.id=r-1\n") {
+		t.Error(out.String())
+	}
+
+	out.Reset()
+	logger.With("svc", "api").WithGroup("db").Error("failed", "err", errors.New("timeout"))
+	if !strings.HasSuffix(out.String(), "slog_test:27 failed svc=api db.err=timeout\n") || !strings.Contains(out.String(), " E] ") {
+		t.Error(out.String())
+	}
+
+	if FromSlogLevel(slog.LevelWarn) != W || FromSlogLevel(slog.LevelError+4) != F || ToSlogLevel(D) != slog.LevelDebug {
+		t.Fail()
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var out bytes.Buffer
+	el := NewWriter(&out, false)
+
+	std := NewStdLogger(el, W)
+	std.Printf("from %s", "std")
+	if !strings.HasSuffix(out.String(), " W] "+strconv.Itoa(pid)+" slog_test:42 from std\n") {
+		t.Error(out.String())
+	}
+
+	out.Reset()
+	flags, prefix := log.Flags(), log.Prefix()
+	log.SetFlags(0)
+	log.SetOutput(NewStdWriter(el, E))
+	log.Println("global")
+	log.SetOutput(os.Stderr)
+	log.SetFlags(flags)
+	log.SetPrefix(prefix)
+	if !strings.HasSuffix(out.String(), " E] "+strconv.Itoa(pid)+" slog_test:51 global\n") {
+		t.Error(out.String())
+	}
+}
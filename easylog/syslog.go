@@ -0,0 +1,216 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Formats of syslog frames
+const (
+	RFC5424 int = iota
+	RFC3164 int = iota
+)
+
+// Syslog facilities, kern(0) is reserved for the kernel
+const (
+	LOG_USER   = 1
+	LOG_DAEMON = 3
+	LOG_LOCAL0 = 16
+	LOG_LOCAL1 = 17
+	LOG_LOCAL2 = 18
+	LOG_LOCAL3 = 19
+	LOG_LOCAL4 = 20
+	LOG_LOCAL5 = 21
+	LOG_LOCAL6 = 22
+	LOG_LOCAL7 = 23
+)
+
+// Syslog severities of D, I, W, E, F and P
+var severities = []int{7, 6, 4, 3, 2, 1}
+
+// Map level to syslog severity, debug(7) info(6) warning(4) err(3) crit(2) alert(1)
+func Severity(level int) int {
+	if level >= 0 && level < len(severities) {
+		return severities[level]
+	}
+	return 5 // notice
+}
+
+// Local syslog sockets tried when Network is empty
+var syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Options of SyslogSink, zero values are defaults
+type SyslogOptions struct {
+	Network  string // "unixgram", "unix", "udp" or "tcp", empty for local syslog socket
+	Addr     string // socket path or host:port
+	Format   int    // RFC5424 or RFC3164
+	Facility int    // LOG_USER if 0
+	Tag      string // app name, base of os.Args[0] if empty
+	Hostname string // os.Hostname() if empty
+	Level    int    // minimum level of sink
+}
+
+// Sink sending syslog frames, a datagram or an octet-counted frame on stream (RFC 6587) per record
+type SyslogSink struct {
+	mtx    sync.Mutex
+	opt    SyslogOptions
+	conn   net.Conn
+	buf    bytes.Buffer
+	frame  bytes.Buffer
+	body   bytes.Buffer
+	closed bool
+}
+
+// Connect to syslog server
+func NewSyslogSink(opt SyslogOptions) (*SyslogSink, error) {
+	if opt.Facility == 0 {
+		opt.Facility = LOG_USER
+	}
+	if opt.Tag == "" {
+		opt.Tag = filepath.Base(os.Args[0])
+	}
+	if opt.Hostname == "" {
+		opt.Hostname, _ = os.Hostname()
+	}
+	if opt.Hostname == "" {
+		opt.Hostname = "-"
+	}
+	s := &SyslogSink{opt: opt}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SyslogSink) connect() error {
+	if s.opt.Network != "" {
+		conn, err := net.Dial(s.opt.Network, s.opt.Addr)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+		return nil
+	}
+	err := errors.New("easylog: no local syslog socket")
+	for _, path := range syslogSockets {
+		for _, network := range []string{"unixgram", "unix"} {
+			var conn net.Conn
+			if conn, err = net.Dial(network, path); err == nil {
+				s.opt.Network, s.opt.Addr = network, path
+				s.conn = conn
+				return nil
+			}
+		}
+	}
+	return err
+}
+
+func (s *SyslogSink) Level() int {
+	return s.opt.Level
+}
+
+// Send one frame, stream connection is redialed once if writing fails
+func (s *SyslogSink) Emit(r *Record) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.closed {
+		return os.ErrClosed
+	}
+	s.format(r)
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+	_, err := s.conn.Write(s.buf.Bytes())
+	if err != nil && s.isStream() {
+		s.conn.Close()
+		s.conn = nil
+		if err = s.connect(); err == nil {
+			_, err = s.conn.Write(s.buf.Bytes())
+		}
+	}
+	return err
+}
+
+func (s *SyslogSink) isStream() bool {
+	switch s.opt.Network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	}
+	return false
+}
+
+// Format frame of r into s.buf
+func (s *SyslogSink) format(r *Record) {
+	s.body.Reset()
+	if r.File != "" {
+		s.body.WriteString(baseName(r.File))
+		s.body.WriteByte(':')
+		s.body.WriteString(strconv.Itoa(r.Line))
+		s.body.WriteByte(' ')
+	}
+	writeTextBody(&s.body, r, "")
+	s.body.Truncate(s.body.Len() - 1) // no linebreak
+
+	pri := s.opt.Facility*8 + Severity(r.Level)
+	frame := &s.frame
+	frame.Reset()
+	frame.WriteByte('<')
+	frame.WriteString(strconv.Itoa(pri))
+	frame.WriteByte('>')
+	if s.opt.Format == RFC3164 {
+		frame.WriteString(r.Time.Format(time.Stamp))
+		frame.WriteByte(' ')
+		frame.WriteString(s.opt.Hostname)
+		frame.WriteByte(' ')
+		frame.WriteString(s.opt.Tag)
+		frame.WriteByte('[')
+		frame.WriteString(strconv.Itoa(r.Pid))
+		frame.WriteString("]: ")
+	} else {
+		frame.WriteString("1 ")
+		frame.WriteString(r.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+		frame.WriteByte(' ')
+		frame.WriteString(s.opt.Hostname)
+		frame.WriteByte(' ')
+		frame.WriteString(s.opt.Tag)
+		frame.WriteByte(' ')
+		frame.WriteString(strconv.Itoa(r.Pid))
+		frame.WriteString(" - - ")
+	}
+	frame.Write(s.body.Bytes())
+
+	s.buf.Reset()
+	if s.isStream() {
+		if s.opt.Format == RFC5424 {
+			s.buf.WriteString(strconv.Itoa(frame.Len()))
+			s.buf.WriteByte(' ')
+			s.buf.Write(frame.Bytes())
+		} else {
+			s.buf.Write(frame.Bytes())
+			s.buf.WriteByte('\n')
+		}
+	} else {
+		s.buf.Write(frame.Bytes())
+	}
+}
+
+func (s *SyslogSink) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.closed = true
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,139 @@
+// An easy using logger for Go, thread-safe with high performance
+package easylog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readPacket(t *testing.T, conn net.PacketConn) string {
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func TestSyslogUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewSyslogSink(SyslogOptions{Network: "udp", Addr: conn.LocalAddr().String(), Tag: "app", Hostname: "host", Facility: LOG_LOCAL0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := NewSinks(false, sink)
+	log.WarnKV("disk low", "free", "1G")
+	log.Close()
+
+	re := regexp.MustCompile(`^<132>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}\S+ host app \d+ - - syslog_test\.go:40 disk low free=1G$`)
+	if frame := readPacket(t, conn); !re.MatchString(frame) {
+		t.Error(frame)
+	}
+}
+
+func TestSyslogUnixgram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewSyslogSink(SyslogOptions{Network: "unixgram", Addr: path, Format: RFC3164, Tag: "app", Hostname: "host"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	NewSinks(false, sink).Error("failed")
+
+	re := regexp.MustCompile(`^<11>\w{3} [ \d]\d \d\d:\d\d:\d\d host app\[\d+\]: syslog_test\.go:62 failed$`)
+	if frame := readPacket(t, conn); !re.MatchString(frame) {
+		t.Error(frame)
+	}
+}
+
+func TestSyslogTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	sink, err := NewSyslogSink(SyslogOptions{Network: "tcp", Addr: ln.Addr().String(), Tag: "app", Hostname: "host"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	log := NewSinks(false, sink)
+	log.Info("one")
+	log.Info("two")
+	sink.Close()
+
+	r := bufio.NewReader(conn)
+	for _, msg := range []string{"one", "two"} {
+		size, err := r.ReadString(' ')
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, err := strconv.Atoi(size[:len(size)-1])
+		if err != nil {
+			t.Fatal(size, err)
+		}
+		frame := make([]byte, n)
+		if _, err := io.ReadFull(r, frame); err != nil || !strings.HasPrefix(string(frame), "<14>1 ") || !strings.HasSuffix(string(frame), " "+msg) {
+			t.Error(string(frame), err)
+		}
+	}
+}
+
+func TestJournalSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewJournalSink(path, "app", D)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	NewSinks(false, sink).ErrorKV("failed", "user-id", 42, "trace", "a\nb")
+
+	data := readPacket(t, conn)
+	for _, field := range []string{"MESSAGE=failed\n", "PRIORITY=3\n", "SYSLOG_IDENTIFIER=app\n", "CODE_LINE=122\n", "USER_ID=42\n",
+		"CODE_FUNC=easylog.TestJournalSink\n"} {
+		if !strings.Contains(data, field) {
+			t.Error(field, data)
+		}
+	}
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], 3)
+	if !strings.HasSuffix(data, "TRACE\n"+string(size[:])+"a\nb\n") {
+		t.Errorf("%q", data)
+	}
+	if !bytes.Contains([]byte(data), []byte("CODE_FILE=")) {
+		t.Error(data)
+	}
+}
@@ -0,0 +1,165 @@
+package fio
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Codec Compression format detected by magic bytes or extension of file
+//
+// zstd or xz can be plugged in by packages like github.com/klauspost/compress/zstd:
+//
+//	fio.RegisterCodec(&fio.Codec{
+//		Name:  "zstd",
+//		Exts:  []string{".zst"},
+//		Magic: [][]byte{{0x28, 0xb5, 0x2f, 0xfd}},
+//		NewReader: func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+//	})
+type Codec struct {
+	Name      string
+	Exts      []string                                  // extensions with dot, e.g. ".gz"
+	Magic     [][]byte                                  // leading bytes of data, any of them matches
+	NewReader func(r io.Reader) (io.Reader, error)      // decoder
+	NewWriter func(w io.Writer) (io.WriteCloser, error) // encoder, nil if not supported
+	WeakMagic bool                                      // magic may start plain text, extension must match too
+}
+
+var codecs struct {
+	mtx  sync.RWMutex
+	list []*Codec
+}
+
+func init() {
+	RegisterCodec(&Codec{
+		Name:  "gzip",
+		Exts:  []string{".gz", ".gzip"},
+		Magic: [][]byte{{0x1f, 0x8b}},
+		NewReader: func(r io.Reader) (io.Reader, error) {
+			return gzip.NewReader(r)
+		},
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+	})
+	RegisterCodec(&Codec{
+		Name:      "bzip2",
+		Exts:      []string{".bz2"},
+		Magic:     [][]byte{[]byte("BZh")},
+		WeakMagic: true,
+		NewReader: func(r io.Reader) (io.Reader, error) {
+			return bzip2.NewReader(r), nil
+		},
+	})
+	RegisterCodec(&Codec{
+		Name:      "zlib",
+		Exts:      []string{".zz", ".zlib"},
+		Magic:     [][]byte{{0x78, 0x01}, {0x78, 0x5e}, {0x78, 0x9c}, {0x78, 0xda}},
+		WeakMagic: true,
+		NewReader: func(r io.Reader) (io.Reader, error) {
+			return zlib.NewReader(r)
+		},
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(w), nil
+		},
+	})
+}
+
+// RegisterCodec Add codec, or replace the one of same name
+func RegisterCodec(c *Codec) {
+	codecs.mtx.Lock()
+	defer codecs.mtx.Unlock()
+	for i, old := range codecs.list {
+		if old.Name == c.Name {
+			codecs.list[i] = c
+			return
+		}
+	}
+	codecs.list = append(codecs.list, c)
+}
+
+// CodecByExt Get codec by extension of path, nil if not compressed
+func CodecByExt(path string) *Codec {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == "" {
+		return nil
+	}
+	codecs.mtx.RLock()
+	defer codecs.mtx.RUnlock()
+	for _, c := range codecs.list {
+		for _, e := range c.Exts {
+			if e == ext {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// CodecByMagic Get codec by leading bytes of data, nil if not compressed
+func CodecByMagic(head []byte) *Codec {
+	codecs.mtx.RLock()
+	defer codecs.mtx.RUnlock()
+	for _, c := range codecs.list {
+		for _, m := range c.Magic {
+			if len(m) > 0 && bytes.HasPrefix(head, m) {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// Longest magic of all codecs
+func magicSize() int {
+	codecs.mtx.RLock()
+	defer codecs.mtx.RUnlock()
+	n := 0
+	for _, c := range codecs.list {
+		for _, m := range c.Magic {
+			if len(m) > n {
+				n = len(m)
+			}
+		}
+	}
+	return n
+}
+
+// Detect codec of data in r by magic bytes, or by extension of path if the codec has no magic
+// Codecs of WeakMagic need both, so text like "BZh..." or "x^2" is not taken as compressed
+func detectCodec(r *bufio.Reader, path string) *Codec {
+	head, _ := r.Peek(magicSize())
+	byExt := CodecByExt(path)
+	if c := CodecByMagic(head); c != nil && (!c.WeakMagic || c == byExt) {
+		return c
+	}
+	if byExt != nil && len(byExt.Magic) == 0 {
+		return byExt
+	}
+	return nil
+}
+
+// Decompress Wrap r by the decoder detected from magic bytes, r is returned buffered if not compressed
+// Codecs of WeakMagic, e.g. bzip2 and zlib, are not detected without extension
+func Decompress(r io.Reader) (*bufio.Reader, error) {
+	return decompress(r, "")
+}
+
+func decompress(r io.Reader, path string) (*bufio.Reader, error) {
+	reader := bufio.NewReaderSize(r, IO_BUF_SIZE)
+	c := detectCodec(reader, path)
+	if c == nil {
+		return reader, nil
+	}
+	dec, err := c.NewReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	return bufio.NewReaderSize(dec, IO_BUF_SIZE), nil
+}
@@ -0,0 +1,123 @@
+package fio
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCodec(t *testing.T) {
+	dir := t.TempDir()
+	content := "first\nsecond\nthird"
+	for _, ext := range []string{".txt", ".gz", ".zz"} {
+		name := filepath.Join(dir, "a"+ext)
+		if err := WriteFile(name, content); err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := os.ReadFile(name)
+		if (ext == ".txt") != (string(raw) == content) {
+			t.Error(ext, "is not encoded by extension")
+		}
+		lines, err := ReadLines(name)
+		if err != nil || strings.Join(lines, "\n") != content {
+			t.Error(ext, lines, err)
+		}
+		if first, err := FirstLine(name); first != "first" || err != nil {
+			t.Error(ext, first, err)
+		}
+		if n, err := ReadLine(name, func(string) {}); n != 3 || err != nil {
+			t.Error(ext, n, err)
+		}
+
+		// gzip is detected by magic without extension, zlib magic is too weak
+		os.Rename(name, name+".data")
+		if lines, _ := ReadLines(name + ".data"); (strings.Join(lines, "\n") == content) != (ext != ".zz") {
+			t.Error(ext, lines)
+		}
+	}
+
+	if err := WriteFile(filepath.Join(dir, "a.bz2"), content); err == nil {
+		t.Error("bzip2 has no encoder")
+	}
+	// bzip2 of "hello\n"
+	bz := []byte("\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\xc1\xc0\x80\xe2\x00\x00\x01\x41\x00\x00\x10\x02\x44\xa0\x00\x30\xcd\x00\xc3\x46\x29\x97\x17\x72\x45\x38\x50\x90\xc1\xc0\x80\xe2")
+	os.WriteFile(filepath.Join(dir, "b.bz2"), bz, 0644)
+	if first, err := FirstLine(filepath.Join(dir, "b.bz2")); first != "hello" || err != nil {
+		t.Error(first, err)
+	}
+	if err := ParallelEach(context.Background(), filepath.Join(dir, "a.gz.data"), ParallelOptions{}, nil); err == nil {
+		t.Error("compressed file can't be split")
+	}
+}
+
+func TestCodecWeakMagic(t *testing.T) {
+	dir := t.TempDir()
+	for _, content := range []string{"BZhello world\nsecond", "x^2 + y^2\nsecond", "x\x01\nsecond"} {
+		name := filepath.Join(dir, "plain.txt")
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		first := strings.SplitN(content, "\n", 2)[0]
+		if lines, err := ReadLines(name); err != nil || strings.Join(lines, "\n") != content {
+			t.Errorf("ReadLines %q %q %v", content, lines, err)
+		}
+		if line, err := FirstLine(name); line != first || err != nil {
+			t.Errorf("FirstLine %q %q %v", content, line, err)
+		}
+		if n, err := ReadBytesLine(name, func([]byte) {}); n != 2 || err != nil {
+			t.Errorf("ReadBytesLine %q %d %v", content, n, err)
+		}
+		if err := ParallelEach(context.Background(), name, ParallelOptions{}, func(int, []byte) error { return nil }); err != nil {
+			t.Errorf("ParallelEach %q %v", content, err)
+		}
+		rr, file, err := OpenRecords(name, RecordOptions{NoHeader: true, LazyQuotes: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !rr.Next() || rr.Record()[0] != first {
+			t.Errorf("OpenRecords %q %q %v", content, rr.Record(), rr.Err())
+		}
+		file.Close()
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	// a toy codec without magic, every byte is stored twice
+	RegisterCodec(&Codec{
+		Name: "twice",
+		Exts: []string{".twice"},
+		NewReader: func(r io.Reader) (io.Reader, error) {
+			data, err := io.ReadAll(r)
+			var out bytes.Buffer
+			for i := 0; i < len(data); i += 2 {
+				out.WriteByte(data[i])
+			}
+			return &out, err
+		},
+	})
+	name := filepath.Join(t.TempDir(), "a.TWICE")
+	os.WriteFile(name, []byte("hhii\n\n"), 0644)
+	if first, err := FirstLine(name); first != "hi" || err != nil {
+		t.Error(first, err)
+	}
+	if CodecByExt("x.twice").Name != "twice" || CodecByExt("x.txt") != nil || CodecByExt("x") != nil {
+		t.Error("CodecByExt")
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte("zipped"))
+	w.Close()
+	r, err := Decompress(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(r); string(data) != "zipped" {
+		t.Error(string(data))
+	}
+}
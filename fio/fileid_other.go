@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package fio
+
+import "os"
+
+// Identity of file is unknown
+func fileID(file *os.File) (uint64, uint64) {
+	return 0, 0
+}
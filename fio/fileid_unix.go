@@ -0,0 +1,20 @@
+//go:build unix
+
+package fio
+
+import (
+	"os"
+	"syscall"
+)
+
+// Device and inode of opened file, 0 if unknown
+func fileID(file *os.File) (uint64, uint64) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, 0
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(st.Dev), uint64(st.Ino)
+	}
+	return 0, 0
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package fio
+
+import (
+	"os"
+	"syscall"
+)
+
+// Volume serial number and file index of opened file, 0 if unknown
+func fileID(file *os.File) (uint64, uint64) {
+	var d syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(syscall.Handle(file.Fd()), &d); err != nil {
+		return 0, 0
+	}
+	return uint64(d.VolumeSerialNumber), uint64(d.FileIndexHigh)<<32 | uint64(d.FileIndexLow)
+}
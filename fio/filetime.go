@@ -0,0 +1,19 @@
+package fio
+
+import (
+	"time"
+)
+
+// FileTimes Times of a file, HasXxx reports whether the platform provides the real value,
+// otherwise the field is filled with the closest one, e.g. Create falls back to Change
+type FileTimes struct {
+	Create time.Time // birth time
+	Modify time.Time // last change of content
+	Access time.Time
+	Change time.Time // last change of content or metadata
+
+	HasCreate bool
+	HasModify bool
+	HasAccess bool
+	HasChange bool
+}
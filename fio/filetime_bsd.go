@@ -0,0 +1,33 @@
+//go:build darwin || freebsd || netbsd
+
+package fio
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// GetFileTime Get times of file by stat, which has birth time on these systems
+func GetFileTime(path string) (FileTimes, error) {
+	finfo, err := os.Stat(path)
+	if err != nil {
+		return FileTimes{}, err
+	}
+	st := finfo.Sys().(*syscall.Stat_t)
+	ft := FileTimes{
+		Modify:    finfo.ModTime(),
+		Access:    time.Unix(st.Atimespec.Unix()),
+		Change:    time.Unix(st.Ctimespec.Unix()),
+		HasModify: true,
+		HasAccess: true,
+		HasChange: true,
+	}
+	// birth time is -1 or 0 if the filesystem doesn't record it
+	if st.Birthtimespec.Sec > 0 {
+		ft.Create, ft.HasCreate = time.Unix(st.Birthtimespec.Unix()), true
+	} else {
+		ft.Create = ft.Change
+	}
+	return ft, nil
+}
@@ -0,0 +1,60 @@
+//go:build linux
+
+package fio
+
+import (
+	"os"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// GetFileTime Get times of file by statx, birth time is reported if the filesystem supports it
+// Falls back to stat on kernels before 4.11, Create is the change time if birth is unknown
+func GetFileTime(path string) (FileTimes, error) {
+	var stx unix.Statx_t
+	err := unix.Statx(unix.AT_FDCWD, path, 0, unix.STATX_BASIC_STATS|unix.STATX_BTIME, &stx)
+	if err == unix.ENOSYS || err == unix.EPERM {
+		return statFileTime(path)
+	}
+	if err != nil {
+		return FileTimes{}, &os.PathError{Op: "statx", Path: path, Err: err}
+	}
+	ts := func(t unix.StatxTimestamp) time.Time {
+		return time.Unix(t.Sec, int64(t.Nsec))
+	}
+	ft := FileTimes{
+		Modify:    ts(stx.Mtime),
+		Access:    ts(stx.Atime),
+		Change:    ts(stx.Ctime),
+		HasModify: stx.Mask&unix.STATX_MTIME != 0,
+		HasAccess: stx.Mask&unix.STATX_ATIME != 0,
+		HasChange: stx.Mask&unix.STATX_CTIME != 0,
+	}
+	if stx.Mask&unix.STATX_BTIME != 0 {
+		ft.Create, ft.HasCreate = ts(stx.Btime), true
+	} else {
+		ft.Create = ft.Change
+	}
+	return ft, nil
+}
+
+// Get times by stat, which has no birth time
+func statFileTime(path string) (FileTimes, error) {
+	finfo, err := os.Stat(path)
+	if err != nil {
+		return FileTimes{}, err
+	}
+	st := finfo.Sys().(*syscall.Stat_t)
+	ft := FileTimes{
+		Modify:    finfo.ModTime(),
+		Access:    time.Unix(st.Atim.Unix()),
+		Change:    time.Unix(st.Ctim.Unix()),
+		HasModify: true,
+		HasAccess: true,
+		HasChange: true,
+	}
+	ft.Create = ft.Change
+	return ft, nil
+}
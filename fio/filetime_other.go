@@ -0,0 +1,17 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !windows
+
+package fio
+
+import (
+	"os"
+)
+
+// GetFileTime Only modification time is portable, others are filled with it
+func GetFileTime(path string) (FileTimes, error) {
+	finfo, err := os.Stat(path)
+	if err != nil {
+		return FileTimes{}, err
+	}
+	mtime := finfo.ModTime()
+	return FileTimes{Create: mtime, Modify: mtime, Access: mtime, Change: mtime, HasModify: true}, nil
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package fio
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// GetFileTime Get times of file from its attributes, windows has no change time so it's Modify
+func GetFileTime(path string) (FileTimes, error) {
+	finfo, err := os.Stat(path)
+	if err != nil {
+		return FileTimes{}, err
+	}
+	attr := finfo.Sys().(*syscall.Win32FileAttributeData)
+	ft := FileTimes{
+		Create:    time.Unix(0, attr.CreationTime.Nanoseconds()),
+		Modify:    time.Unix(0, attr.LastWriteTime.Nanoseconds()),
+		Access:    time.Unix(0, attr.LastAccessTime.Nanoseconds()),
+		HasCreate: true,
+		HasModify: true,
+		HasAccess: true,
+	}
+	ft.Change = ft.Modify
+	return ft, nil
+}
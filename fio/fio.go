@@ -2,14 +2,12 @@ package fio
 
 import (
 	"bufio"
-	"errors"
+	"bytes"
+	"fmt"
 	"io"
 	"os"
-	"strings"
-	"syscall"
-	"time"
-
-	"github.com/alexloser/goaux/utils"
+	"path/filepath"
+	"runtime"
 )
 
 // Capacity in bytes
@@ -30,12 +28,17 @@ const (
 )
 
 // MakeReader Use defer file.Close() after using this if err is nil
+// Compressed file is decoded transparently, see Codec
 func MakeReader(path string) (*os.File, *bufio.Reader, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return file, nil, err
 	}
-	reader := bufio.NewReaderSize(file, 8192)
+	reader, err := decompress(file, path)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
 	return file, reader, err
 }
 
@@ -46,23 +49,7 @@ func ReadBytesLine(path string, callback func(bstr []byte)) (uint, error) {
 		return 0, err
 	}
 	defer file.Close()
-	var nlines uint = 0
-	for {
-		bs, err := reader.ReadSlice(LINE_END)
-		switch err {
-		case nil:
-			nlines++
-			if len(bs) > 0 {
-				callback(bs[0 : len(bs)-1]) // strip
-			} else {
-				callback(bs)
-			}
-		case io.EOF:
-			return nlines, nil
-		default:
-			return nlines, err
-		}
-	}
+	return EachLine(reader, LineOptions{}, callback)
 }
 
 // ReadLine Read file by line, need a callback accepted string pointer of line
@@ -72,51 +59,46 @@ func ReadLine(path string, callback func(str string)) (uint, error) {
 		return 0, err
 	}
 	defer file.Close()
-	var nlines uint = 0
-	for {
-		str, err := reader.ReadString(LINE_END)
-		switch err {
-		case nil:
-			nlines++
-			if len(str) > 0 {
-				callback(str[0 : len(str)-1]) // strip
-			} else {
-				callback(str)
-			}
-		case io.EOF:
-			return nlines, nil
-		default:
-			return nlines, err
-		}
-	}
-
+	return EachLine(reader, LineOptions{}, func(line []byte) {
+		callback(string(line))
+	})
 }
 
 // FirstLine Get first line of text file
 func FirstLine(path string) (line string, err error) {
-	file, err := os.Open(path)
+	file, reader, err := MakeReader(path)
 	if err != nil {
 		return
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	if scanner.Scan() {
-		line = scanner.Text()
+	lr := NewLineReader(reader, LineOptions{End: 1, StripCR: true})
+	if lr.Next() {
+		line = lr.Text()
 	}
-
-	return
+	return line, lr.Err()
 }
 
-// ReadLines Get all lines in text file
+// ReadLines Get all lines in text file split by "\n", an empty line follows the last linebreak
 func ReadLines(path string) ([]string, error) {
-	content, err := os.ReadFile(path)
-
+	file, reader, err := MakeReader(path)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
-	return strings.Split(string(content), string(LINE_END)), nil
+	var lines []string
+	ended := true // like strings.Split, empty file is one empty line
+	lr := NewLineReader(reader, LineOptions{KeepEOL: true})
+	for lr.Next() {
+		line := lr.Bytes()
+		ended = line[len(line)-1] == LINE_END
+		lines = append(lines, string(bytes.TrimSuffix(line, []byte{LINE_END})))
+	}
+	if ended {
+		lines = append(lines, "")
+	}
+	return lines, lr.Err()
 }
 
 func HasBOM(path string) bool {
@@ -131,32 +113,92 @@ func HasBOM(path string) bool {
 	return false
 }
 
-func WriteFile(path string, data interface{}) error {
-	file, _ := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+// WriteFile Replace file atomically by data of string, []byte, io.Reader or fmt.Stringer
+// Data is compressed if extension of path belongs to a Codec, e.g. ".gz"
+// Data is written into a temp file in the same directory, synced and renamed over path,
+// so readers see either the old or the new content, permissions of existing file are kept
+func WriteFile(path string, data interface{}) (err error) {
+	if info, e := os.Lstat(path); e == nil && info.Mode()&os.ModeSymlink != 0 {
+		if path, err = filepath.EvalSymlinks(path); err != nil {
+			return err
+		}
+	}
+	perm := os.FileMode(0644)
+	if info, e := os.Stat(path); e == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
 	defer func() {
-		file.Sync()
-		file.Close()
+		if err != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
 	}()
-	switch data.(type) {
+
+	var w io.Writer = file
+	var enc io.WriteCloser
+	if c := CodecByExt(path); c != nil {
+		if c.NewWriter == nil {
+			return fmt.Errorf("fio: writing %s is not supported", c.Name)
+		}
+		if enc, err = c.NewWriter(file); err != nil {
+			return err
+		}
+		w = enc
+	}
+
+	switch v := data.(type) {
 	case string:
-		file.WriteString(data.(string))
-		return nil
+		_, err = io.WriteString(w, v)
 	case []byte:
-		file.Write(data.([]byte))
-		return nil
+		_, err = w.Write(v)
+	case io.Reader:
+		_, err = io.Copy(w, v)
+	case fmt.Stringer:
+		_, err = io.WriteString(w, v.String())
 	default:
-		return errors.New("Invalid data type")
+		err = fmt.Errorf("Invalid data type %T", data)
 	}
+	if err != nil {
+		return err
+	}
+	if enc != nil {
+		if err = enc.Close(); err != nil {
+			return err
+		}
+	}
+	if err = file.Chmod(perm); err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(file.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(dir)
 }
 
-func GetFileTime(path string) (time.Time, time.Time, time.Time, error) {
-	finfo, err := os.Stat(path)
+// Make rename in dir durable, directories can't be synced on windows
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
 	if err != nil {
-		return time.Unix(0, 0), time.Unix(0, 0), time.Unix(0, 0), err
+		return err
+	}
+	err = d.Sync()
+	if e := d.Close(); err == nil {
+		err = e
 	}
-	winFileAttr := finfo.Sys().(*syscall.Win32FileAttributeData)
-	ctime := utils.SecondToTime(winFileAttr.CreationTime.Nanoseconds() / 1e9)
-	mtime := utils.SecondToTime(winFileAttr.LastWriteTime.Nanoseconds() / 1e9)
-	atime := utils.SecondToTime(winFileAttr.LastAccessTime.Nanoseconds() / 1e9)
-	return ctime, mtime, atime, err
+	return err
 }
@@ -2,8 +2,11 @@ package fio
 
 import (
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/alexloser/goaux/fs"
 )
@@ -53,3 +56,81 @@ func TestFio(t *testing.T) {
 		t.Fail()
 	}
 }
+
+type stringer struct{}
+
+func (stringer) String() string {
+	return "stringer"
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "a.conf")
+	cases := []struct {
+		data interface{}
+		want string
+	}{
+		{"string", "string"},
+		{[]byte("bytes"), "bytes"},
+		{strings.NewReader("reader"), "reader"},
+		{stringer{}, "stringer"},
+	}
+	for _, c := range cases {
+		if err := WriteFile(name, c.data); err != nil {
+			t.Fatal(err)
+		}
+		if content, _ := os.ReadFile(name); string(content) != c.want {
+			t.Error(string(content))
+		}
+	}
+
+	if runtime.GOOS != "windows" {
+		os.Chmod(name, 0600)
+		if err := WriteFile(name, "mode"); err != nil {
+			t.Fatal(err)
+		}
+		if info, _ := os.Stat(name); info.Mode().Perm() != 0600 {
+			t.Error(info.Mode())
+		}
+	}
+
+	if err := WriteFile(name, 42); err == nil {
+		t.Error("int is not supported")
+	}
+	if err := WriteFile(filepath.Join(dir, "none", "a.conf"), "x"); err == nil {
+		t.Error("no such directory")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Error("temp file is left", files)
+	}
+}
+
+func TestGetFileTime(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "a.txt")
+	before := time.Now().Add(-time.Second)
+	os.WriteFile(name, []byte("x"), 0644)
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	os.Chtimes(name, mtime, mtime)
+
+	ft, err := GetFileTime(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ft.HasModify || !ft.Modify.Equal(mtime) {
+		t.Error(ft.Modify)
+	}
+	if ft.HasAccess && !ft.Access.Equal(mtime) {
+		t.Error(ft.Access)
+	}
+	if ft.HasChange && ft.Change.Before(before) {
+		t.Error(ft.Change)
+	}
+	if ft.HasCreate && ft.Create.Before(before) {
+		t.Error(ft.Create)
+	}
+	t.Logf("%+v", ft)
+
+	if _, err := GetFileTime(name + ".none"); !os.IsNotExist(err) {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,194 @@
+package fio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrStop Returned by callback of WalkLines to stop reading without error
+var ErrStop = errors.New("fio: stop")
+
+// ErrLineTooLong Line is longer than LineOptions.MaxLength
+var ErrLineTooLong = errors.New("fio: line too long")
+
+// LineOptions Options of LineReader, zero value yields every line with "\n" stripped
+type LineOptions struct {
+	MaxLength int      // max bytes of a line without linebreak, 0 for no limit
+	KeepEOL   bool     // keep "\n" or "\r\n" at the end of lines
+	StripCR   bool     // strip "\r\n" instead of only "\n"
+	SkipBOM   bool     // remove BOM at the beginning of the first line
+	SkipBlank bool     // skip lines of only whitespace
+	Comments  []string // skip lines starting with any of these prefixes after leading whitespace
+	Start     int      // first line number to yield, starting from 1
+	End       int      // last line number to yield, 0 for no limit
+}
+
+// LineReader Read lines of any length from io.Reader, the last line may have no linebreak
+//
+//	lr := NewLineReader(r, LineOptions{SkipBlank: true})
+//	for lr.Next() {
+//		use(lr.Number(), lr.Text())
+//	}
+//	err := lr.Err()
+type LineReader struct {
+	r    *bufio.Reader
+	opt  LineOptions
+	line []byte
+	buf  []byte
+	num  int
+	err  error
+	done bool
+}
+
+// NewLineReader Create line reader on r
+func NewLineReader(r io.Reader, opt LineOptions) *LineReader {
+	return &LineReader{r: bufio.NewReaderSize(r, IO_BUF_SIZE), opt: opt}
+}
+
+// Next Advance to the next line, false at the end, after End or on error
+func (lr *LineReader) Next() bool {
+	for !lr.done {
+		if lr.opt.End > 0 && lr.num >= lr.opt.End {
+			break
+		}
+		line, err := lr.readLine()
+		if err != nil {
+			if err != io.EOF {
+				lr.err = err
+			}
+			break
+		}
+		lr.num++
+		if lr.num == 1 && lr.opt.SkipBOM {
+			line = bytes.TrimPrefix(line, []byte(BOM))
+		}
+		if lr.num < lr.opt.Start {
+			continue
+		}
+		content := trimEOL(line)
+		if lr.skip(content) {
+			continue
+		}
+		if lr.opt.KeepEOL {
+			lr.line = line
+		} else if lr.opt.StripCR {
+			lr.line = content
+		} else {
+			lr.line = bytes.TrimSuffix(line, []byte{LINE_END})
+		}
+		return true
+	}
+	lr.done = true
+	lr.line = nil
+	return false
+}
+
+// Bytes Current line, valid until the next call of Next
+func (lr *LineReader) Bytes() []byte {
+	return lr.line
+}
+
+// Text Current line as string
+func (lr *LineReader) Text() string {
+	return string(lr.line)
+}
+
+// Number Line number of current line, starting from 1, skipped lines are counted too
+func (lr *LineReader) Number() int {
+	return lr.num
+}
+
+// Err First error except io.EOF
+func (lr *LineReader) Err() error {
+	return lr.err
+}
+
+func (lr *LineReader) skip(content []byte) bool {
+	if !lr.opt.SkipBlank && len(lr.opt.Comments) == 0 {
+		return false
+	}
+	trimmed := bytes.TrimLeft(content, " \t\r\v\f")
+	if lr.opt.SkipBlank && len(trimmed) == 0 {
+		return true
+	}
+	for _, prefix := range lr.opt.Comments {
+		if prefix != "" && bytes.HasPrefix(trimmed, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Read one line with its linebreak, io.EOF only if nothing is left
+func (lr *LineReader) readLine() ([]byte, error) {
+	max := lr.opt.MaxLength
+	lr.buf = lr.buf[:0]
+	for {
+		frag, err := lr.r.ReadSlice(LINE_END)
+		if err == nil && len(lr.buf) == 0 {
+			if max > 0 && len(trimEOL(frag)) > max {
+				return nil, fmt.Errorf("%w: line %d", ErrLineTooLong, lr.num+1)
+			}
+			return frag, nil // no copy for most lines
+		}
+		lr.buf = append(lr.buf, frag...)
+		// a partial line may end with '\r' of "\r\n"
+		if max > 0 && (len(lr.buf) > max+1 || (err != bufio.ErrBufferFull && len(trimEOL(lr.buf)) > max)) {
+			return nil, fmt.Errorf("%w: line %d", ErrLineTooLong, lr.num+1)
+		}
+		switch err {
+		case nil:
+			return lr.buf, nil
+		case bufio.ErrBufferFull:
+			continue
+		case io.EOF:
+			if len(lr.buf) > 0 {
+				return lr.buf, nil
+			}
+			return nil, io.EOF
+		default:
+			return nil, err
+		}
+	}
+}
+
+// Strip "\n" or "\r\n"
+func trimEOL(line []byte) []byte {
+	if n := len(line); n > 0 && line[n-1] == LINE_END {
+		line = line[:n-1]
+		if n > 1 && line[n-2] == '\r' {
+			line = line[:n-2]
+		}
+	}
+	return line
+}
+
+// EachLine Call callback with every line of r, return number of lines passed to callback
+// The slice is reused, copy it if it's kept after callback returns
+func EachLine(r io.Reader, opt LineOptions, callback func(line []byte)) (uint, error) {
+	var n uint
+	lr := NewLineReader(r, opt)
+	for lr.Next() {
+		n++
+		callback(lr.Bytes())
+	}
+	return n, lr.Err()
+}
+
+// WalkLines Call fn with number and content of every line of r until fn returns an error
+// ErrStop stops walking and nil is returned, other errors are returned as is
+func WalkLines(r io.Reader, opt LineOptions, fn func(num int, line []byte) error) error {
+	lr := NewLineReader(r, opt)
+	for lr.Next() {
+		if err := fn(lr.Number(), lr.Bytes()); err != nil {
+			if err == ErrStop {
+				return nil
+			}
+			return err
+		}
+	}
+	return lr.Err()
+}
@@ -0,0 +1,105 @@
+package fio
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, input string, opt LineOptions) []string {
+	var lines []string
+	lr := NewLineReader(strings.NewReader(input), opt)
+	for lr.Next() {
+		lines = append(lines, lr.Text())
+	}
+	if lr.Err() != nil {
+		t.Fatal(lr.Err())
+	}
+	return lines
+}
+
+func TestLineReader(t *testing.T) {
+	long := strings.Repeat("x", 3*IO_BUF_SIZE)
+	cases := []struct {
+		input string
+		opt   LineOptions
+		want  []string
+	}{
+		{"", LineOptions{}, nil},
+		{"a\nb", LineOptions{}, []string{"a", "b"}},
+		{"a\r\nb\n", LineOptions{}, []string{"a\r", "b"}},
+		{"a\r\nb\n", LineOptions{StripCR: true}, []string{"a", "b"}},
+		{"a\r\nb\n", LineOptions{KeepEOL: true}, []string{"a\r\n", "b\n"}},
+		{BOM + "a\n", LineOptions{}, []string{BOM + "a"}},
+		{BOM + "a\n", LineOptions{SkipBOM: true}, []string{"a"}},
+		{"a\n \t\n\nb\n", LineOptions{SkipBlank: true}, []string{"a", "b"}},
+		{"# c\na\n  // d\nb", LineOptions{Comments: []string{"#", "//"}}, []string{"a", "b"}},
+		{"1\n2\n3\n4\n5\n", LineOptions{Start: 2, End: 4}, []string{"2", "3", "4"}},
+		{long + "\n" + long, LineOptions{}, []string{long, long}},
+		{"abc\r\nab\n", LineOptions{MaxLength: 3, StripCR: true}, []string{"abc", "ab"}},
+	}
+	for i, c := range cases {
+		if got := readAll(t, c.input, c.opt); strings.Join(got, "|") != strings.Join(c.want, "|") || len(got) != len(c.want) {
+			t.Errorf("case %d: %q", i, got)
+		}
+	}
+
+	lr := NewLineReader(strings.NewReader("ok\n"+long+"\n"), LineOptions{MaxLength: IO_BUF_SIZE})
+	if !lr.Next() || lr.Next() || !errors.Is(lr.Err(), ErrLineTooLong) || lr.Number() != 1 {
+		t.Error(lr.Err())
+	}
+}
+
+func TestWalkLines(t *testing.T) {
+	var nums []int
+	err := WalkLines(strings.NewReader("a\n\nb\nc\n"), LineOptions{SkipBlank: true}, func(num int, line []byte) error {
+		nums = append(nums, num)
+		if string(line) == "b" {
+			return ErrStop
+		}
+		return nil
+	})
+	if err != nil || len(nums) != 2 || nums[0] != 1 || nums[1] != 3 {
+		t.Error(nums, err)
+	}
+
+	failed := errors.New("failed")
+	err = WalkLines(strings.NewReader("a\nb\n"), LineOptions{}, func(int, []byte) error { return failed })
+	if err != failed {
+		t.Error(err)
+	}
+}
+
+func TestReadLineLastLine(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "a.txt")
+	long := strings.Repeat("y", 2*IO_BUF_SIZE)
+	os.WriteFile(name, []byte("first\r\n"+long+"\nlast"), 0644)
+
+	var bytesLines, strLines []string
+	n1, err1 := ReadBytesLine(name, func(line []byte) { bytesLines = append(bytesLines, string(line)) })
+	n2, err2 := ReadLine(name, func(line string) { strLines = append(strLines, line) })
+	if err1 != nil || err2 != nil || n1 != 3 || n2 != 3 {
+		t.Fatal(n1, err1, n2, err2)
+	}
+	for _, lines := range [][]string{bytesLines, strLines} {
+		if lines[0] != "first\r" || lines[1] != long || lines[2] != "last" {
+			t.Errorf("%.20q", lines)
+		}
+	}
+	if lines, err := ReadLines(name); err != nil || len(lines) != 3 || lines[0] != "first\r" || lines[2] != "last" {
+		t.Error(len(lines), err)
+	}
+	if first, err := FirstLine(name); first != "first" || err != nil {
+		t.Error(first, err)
+	}
+	// same as splitting content by "\n"
+	for _, content := range []string{"", "\n", "a\r\nb\n", "a\n\nb"} {
+		os.WriteFile(name, []byte(content), 0644)
+		lines, err := ReadLines(name)
+		if want := strings.Split(content, "\n"); err != nil || strings.Join(lines, "|") != strings.Join(want, "|") || len(lines) != len(want) {
+			t.Errorf("%q %q %v", content, lines, err)
+		}
+	}
+}
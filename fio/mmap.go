@@ -0,0 +1,187 @@
+package fio
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sort"
+)
+
+// MmapFile Read-only content of a file, mapped into memory on linux or read into memory elsewhere
+// Slices returned by its methods share the mapping, they must not be used after Close
+type MmapFile struct {
+	data   []byte
+	unmap  func([]byte) error // nil if data is not mapped
+	closed bool
+}
+
+// OpenMmap Map file at path into memory
+func OpenMmap(path string) (*MmapFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return mmapFile(file, info.Size())
+}
+
+// Bytes Whole content of file
+func (m *MmapFile) Bytes() []byte {
+	return m.data
+}
+
+// Len Size of file
+func (m *MmapFile) Len() int {
+	return len(m.data)
+}
+
+// ReadAt Copy content at off into p, implements io.ReaderAt
+func (m *MmapFile) ReadAt(p []byte, off int64) (int, error) {
+	if m.closed {
+		return 0, os.ErrClosed
+	}
+	if off < 0 {
+		return 0, os.ErrInvalid
+	}
+	if off >= int64(len(m.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Close Unmap the file
+func (m *MmapFile) Close() error {
+	if m.closed {
+		return os.ErrClosed
+	}
+	m.closed = true
+	data := m.data
+	m.data = nil
+	if m.unmap != nil && len(data) > 0 {
+		return m.unmap(data)
+	}
+	return nil
+}
+
+// Lines Iterate lines from the beginning
+func (m *MmapFile) Lines() *MmapLines {
+	return m.LinesFrom(0)
+}
+
+// LinesFrom Iterate lines from the line containing off, e.g. an offset returned by Search
+func (m *MmapFile) LinesFrom(off int64) *MmapLines {
+	return &MmapLines{data: m.data, next: m.lineStart(off)}
+}
+
+// LineAt Line containing off without linebreak, nil if off is out of file
+func (m *MmapFile) LineAt(off int64) []byte {
+	if off < 0 || off >= int64(len(m.data)) {
+		return nil
+	}
+	start := m.lineStart(off)
+	end := bytes.IndexByte(m.data[start:], LINE_END)
+	if end < 0 {
+		return m.data[start:]
+	}
+	return trimEOL(m.data[start : start+end+1])
+}
+
+// Start of line containing off
+func (m *MmapFile) lineStart(off int64) int {
+	if off <= 0 {
+		return 0
+	}
+	if off > int64(len(m.data)) {
+		off = int64(len(m.data))
+	}
+	return bytes.LastIndexByte(m.data[:off], LINE_END) + 1
+}
+
+// Start of the first line at or after p
+func (m *MmapFile) nextLineStart(p int) int {
+	if p == 0 {
+		return 0
+	}
+	i := bytes.IndexByte(m.data[p-1:], LINE_END)
+	if i < 0 {
+		return len(m.data)
+	}
+	return p + i
+}
+
+// Search Binary search in file of sorted lines, cmp returns <0, 0 or >0 if line is less than,
+// equal to or greater than the target. Return offset of the first line not less than target,
+// which is Len() if all lines are less, and whether that line equals target
+//
+//	off, found := m.Search(func(line []byte) int {
+//		key, _, _ := bytes.Cut(line, []byte{'\t'})
+//		return bytes.Compare(key, target)
+//	})
+func (m *MmapFile) Search(cmp func(line []byte) int) (int64, bool) {
+	n := len(m.data)
+	line := func(start int) []byte {
+		end := bytes.IndexByte(m.data[start:], LINE_END)
+		if end < 0 {
+			return m.data[start:]
+		}
+		return trimEOL(m.data[start : start+end+1])
+	}
+	p := sort.Search(n+1, func(p int) bool {
+		start := m.nextLineStart(p)
+		return start >= n || cmp(line(start)) >= 0
+	})
+	start := m.nextLineStart(p)
+	if start >= n {
+		return int64(n), false
+	}
+	return int64(start), cmp(line(start)) == 0
+}
+
+// MmapLines Iterator of lines in MmapFile without copying
+//
+//	lines := m.Lines()
+//	for lines.Next() {
+//		use(lines.Offset(), lines.Bytes())
+//	}
+type MmapLines struct {
+	data  []byte
+	next  int
+	start int
+	line  []byte
+}
+
+// Next Advance to the next line, the last line may have no linebreak
+func (it *MmapLines) Next() bool {
+	if it.next >= len(it.data) {
+		it.line = nil
+		return false
+	}
+	it.start = it.next
+	rest := it.data[it.next:]
+	if end := bytes.IndexByte(rest, LINE_END); end >= 0 {
+		it.line = trimEOL(rest[:end+1])
+		it.next += end + 1
+	} else {
+		it.line = rest
+		it.next = len(it.data)
+	}
+	return true
+}
+
+// Bytes Current line without linebreak, a slice of the mapping
+func (it *MmapLines) Bytes() []byte {
+	return it.line
+}
+
+// Offset Offset of current line in file
+func (it *MmapLines) Offset() int64 {
+	return int64(it.start)
+}
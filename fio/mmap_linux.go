@@ -0,0 +1,23 @@
+//go:build linux
+
+package fio
+
+import (
+	"os"
+	"syscall"
+)
+
+// Map file by mmap, an empty file can't be mapped and is empty data
+func mmapFile(file *os.File, size int64) (*MmapFile, error) {
+	if size == 0 {
+		return &MmapFile{}, nil
+	}
+	if int64(int(size)) != size {
+		return nil, &os.PathError{Op: "mmap", Path: file.Name(), Err: syscall.EFBIG}
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, &os.PathError{Op: "mmap", Path: file.Name(), Err: err}
+	}
+	return &MmapFile{data: data, unmap: syscall.Munmap}, nil
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package fio
+
+import (
+	"io"
+	"os"
+)
+
+// No mmap, file is read into memory
+func mmapFile(file *os.File, size int64) (*MmapFile, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(file, data); err != nil {
+		return nil, err
+	}
+	return &MmapFile{data: data}, nil
+}
@@ -0,0 +1,112 @@
+package fio
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMmapFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sorted.txt")
+	content := "apple\t1\nbanana\t2\r\ncherry\t3\ndate\t4\nfig\t5"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if m.Len() != len(content) || string(m.Bytes()) != content {
+		t.Fatalf("content %q", m.Bytes())
+	}
+
+	var _ io.ReaderAt = m
+	buf := make([]byte, 6)
+	if n, err := m.ReadAt(buf, 8); err != nil || string(buf[:n]) != "banana" {
+		t.Errorf("ReadAt %q %v", buf[:n], err)
+	}
+	if n, err := m.ReadAt(buf, int64(len(content)-3)); err != io.EOF || string(buf[:n]) != "g\t5" {
+		t.Errorf("ReadAt at end %q %v", buf[:n], err)
+	}
+
+	var lines []string
+	var offsets []int64
+	it := m.Lines()
+	for it.Next() {
+		lines = append(lines, string(it.Bytes()))
+		offsets = append(offsets, it.Offset())
+	}
+	want := []string{"apple\t1", "banana\t2", "cherry\t3", "date\t4", "fig\t5"}
+	if len(lines) != len(want) {
+		t.Fatalf("lines %q", lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d %q != %q", i, lines[i], want[i])
+		}
+		if string(m.LineAt(offsets[i]+1)) != want[i] {
+			t.Errorf("LineAt %d %q", offsets[i]+1, m.LineAt(offsets[i]+1))
+		}
+	}
+
+	search := func(key string) (int64, bool) {
+		return m.Search(func(line []byte) int {
+			k, _, _ := bytes.Cut(line, []byte{'\t'})
+			return bytes.Compare(k, []byte(key))
+		})
+	}
+	for i, key := range []string{"apple", "banana", "cherry", "date", "fig"} {
+		if off, found := search(key); !found || off != offsets[i] {
+			t.Errorf("Search %s %d %v", key, off, found)
+		}
+	}
+	if off, found := search("coconut"); found || off != offsets[3] {
+		t.Errorf("Search coconut %d %v", off, found)
+	}
+	if off, found := search("aaa"); found || off != 0 {
+		t.Errorf("Search aaa %d %v", off, found)
+	}
+	if off, found := search("zzz"); found || off != int64(len(content)) {
+		t.Errorf("Search zzz %d %v", off, found)
+	}
+	it = m.LinesFrom(offsets[3] + 2)
+	if !it.Next() || string(it.Bytes()) != "date\t4" || !it.Next() || string(it.Bytes()) != "fig\t5" || it.Next() {
+		t.Error("LinesFrom")
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ReadAt(buf, 0); err != os.ErrClosed {
+		t.Errorf("ReadAt after Close %v", err)
+	}
+	if err := m.Close(); err != os.ErrClosed {
+		t.Errorf("Close twice %v", err)
+	}
+}
+
+func TestMmapFileEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := OpenMmap(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Len() != 0 || m.Lines().Next() || m.LineAt(0) != nil {
+		t.Error("empty file has content")
+	}
+	if off, found := m.Search(func([]byte) int { return 0 }); found || off != 0 {
+		t.Errorf("Search %d %v", off, found)
+	}
+	if err := m.Close(); err != nil {
+		t.Error(err)
+	}
+	if _, err := OpenMmap(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("missing file opened")
+	}
+}
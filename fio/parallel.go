@@ -0,0 +1,255 @@
+package fio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// Default bytes of one chunk of ParallelLines
+const CHUNK_SIZE = 4 * MB
+
+// ParallelOptions Options of ParallelLines, zero values are defaults
+type ParallelOptions struct {
+	Workers   int   // goroutines processing chunks, runtime.NumCPU() if 0
+	ChunkSize int64 // bytes of a chunk before aligning to lines, CHUNK_SIZE if 0
+	Ordered   bool  // collect results in order of lines, or as soon as a chunk is done
+}
+
+type lineResult[T any] struct {
+	num   int
+	value T
+}
+
+type chunkResult[T any] struct {
+	idx     int
+	results []lineResult[T]
+}
+
+// ParallelLines Split file into chunks aligned to lines and call fn with every line by several workers
+// Line numbers start from 1 and are counted over the whole file, linebreaks are stripped,
+// and line is only valid during the call. Results of fn are passed to collect on the calling
+// goroutine, in order of lines if opt.Ordered is set, collect can be nil
+// The first error of fn or collect cancels all workers and is returned, so is ctx.Err()
+// Compressed files can't be split and are rejected
+func ParallelLines[T any](ctx context.Context, path string, opt ParallelOptions,
+	fn func(num int, line []byte) (T, error), collect func(num int, value T) error) error {
+	if opt.Workers <= 0 {
+		opt.Workers = runtime.NumCPU()
+	}
+	if opt.ChunkSize <= 0 {
+		opt.ChunkSize = CHUNK_SIZE
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+	if c := detectCodec(bufio.NewReader(io.NewSectionReader(file, 0, size)), path); c != nil {
+		return fmt.Errorf("fio: %s is compressed by %s, use ReadBytesLine instead", path, c.Name)
+	}
+	nchunks := int((size + opt.ChunkSize - 1) / opt.ChunkSize)
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var firstErr error
+	var errOnce sync.Once
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	// starts[i] is the number of lines before chunk i, valid after ready[i] is closed
+	starts := make([]int, nchunks+1)
+	ready := make([]chan struct{}, nchunks+1)
+	for i := range ready {
+		ready[i] = make(chan struct{})
+	}
+	close(ready[0])
+
+	// chunks in flight, limits memory of chunks waiting for ordered delivery
+	window := make(chan struct{}, 2*opt.Workers)
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := 0; i < nchunks; i++ {
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make(chan chunkResult[T], opt.Workers)
+	var wg sync.WaitGroup
+	for w := 0; w < opt.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var buf []byte
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				data, b, err := readChunk(file, i, opt.ChunkSize, size, buf)
+				buf = b
+				if err != nil {
+					fail(err)
+					continue
+				}
+				count := bytes.Count(data, []byte{LINE_END})
+				if len(data) > 0 && data[len(data)-1] != LINE_END {
+					count++
+				}
+				select {
+				case <-ready[i]:
+				case <-ctx.Done():
+					continue
+				}
+				starts[i+1] = starts[i] + count
+				close(ready[i+1])
+
+				out := chunkResult[T]{idx: i}
+				if collect != nil {
+					out.results = make([]lineResult[T], 0, count)
+				}
+				num := starts[i]
+				for len(data) > 0 && ctx.Err() == nil {
+					line := data
+					if pos := bytes.IndexByte(data, LINE_END); pos >= 0 {
+						line, data = data[:pos+1], data[pos+1:]
+					} else {
+						data = nil
+					}
+					num++
+					value, err := fn(num, trimEOL(line))
+					if err != nil {
+						fail(err)
+						break
+					}
+					if collect != nil {
+						out.results = append(out.results, lineResult[T]{num, value})
+					}
+				}
+				select {
+				case results <- out:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	deliver := func(out []lineResult[T]) {
+		<-window
+		if collect == nil || ctx.Err() != nil {
+			return
+		}
+		for _, r := range out {
+			if err := collect(r.num, r.value); err != nil {
+				fail(err)
+				return
+			}
+		}
+	}
+	pending := make(map[int][]lineResult[T])
+	next := 0
+	for out := range results {
+		if !opt.Ordered {
+			deliver(out.results)
+			continue
+		}
+		pending[out.idx] = out.results
+		for {
+			rs, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			deliver(rs)
+			next++
+		}
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return parent.Err()
+}
+
+// ParallelEach ParallelLines without results
+func ParallelEach(ctx context.Context, path string, opt ParallelOptions, fn func(num int, line []byte) error) error {
+	return ParallelLines(ctx, path, opt, func(num int, line []byte) (struct{}, error) {
+		return struct{}{}, fn(num, line)
+	}, nil)
+}
+
+// Read lines starting in chunk i, the line crossing the end is read completely
+// The first partial line belongs to the previous chunk, buf is reused and returned
+func readChunk(file *os.File, i int, chunkSize, size int64, buf []byte) ([]byte, []byte, error) {
+	lo := int64(i) * chunkSize
+	hi := lo + chunkSize
+	if hi > size {
+		hi = size
+	}
+	from := lo
+	if lo > 0 {
+		from = lo - 1 // see whether chunk starts at a line
+	}
+	if int64(cap(buf)) < hi-from {
+		buf = make([]byte, hi-from, hi-from+IO_BUF_SIZE)
+	}
+	buf = buf[:hi-from]
+	n, err := file.ReadAt(buf, from)
+	if err != nil && err != io.EOF {
+		return nil, buf, err
+	}
+	buf = buf[:n]
+
+	begin := 0
+	if lo > 0 {
+		pos := bytes.IndexByte(buf, LINE_END)
+		if pos < 0 {
+			return nil, buf, nil // in the middle of a long line
+		}
+		begin = pos + 1
+	}
+	for off := hi; off < size && (len(buf) == 0 || buf[len(buf)-1] != LINE_END); {
+		var more [IO_BUF_SIZE]byte
+		n, err := file.ReadAt(more[:], off)
+		if n == 0 && err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, buf, err
+		}
+		if pos := bytes.IndexByte(more[:n], LINE_END); pos >= 0 {
+			n = pos + 1
+		}
+		buf = append(buf, more[:n]...)
+		off += int64(n)
+	}
+	return buf[begin:], buf, nil
+}
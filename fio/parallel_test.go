@@ -0,0 +1,118 @@
+package fio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// Write n lines of different lengths, line i is "i<TAB>xxx..."
+func makeLines(tb testing.TB, n int, final bool) string {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		sb.WriteString(strconv.Itoa(i))
+		sb.WriteByte('\t')
+		sb.WriteString(strings.Repeat("x", i%97))
+		if i < n || final {
+			sb.WriteByte('\n')
+		}
+	}
+	name := filepath.Join(tb.TempDir(), "lines.tsv")
+	if err := os.WriteFile(name, []byte(sb.String()), 0644); err != nil {
+		tb.Fatal(err)
+	}
+	return name
+}
+
+func TestParallelLines(t *testing.T) {
+	for _, final := range []bool{true, false} {
+		name := makeLines(t, 5000, final)
+		for _, chunk := range []int64{13, 100, 4096, CHUNK_SIZE} {
+			for _, ordered := range []bool{true, false} {
+				seen := make([]bool, 5001)
+				last := 0
+				err := ParallelLines(context.Background(), name, ParallelOptions{Workers: 4, ChunkSize: chunk, Ordered: ordered},
+					func(num int, line []byte) (int, error) {
+						first, _, _ := strings.Cut(string(line), "\t")
+						return strconv.Atoi(first)
+					},
+					func(num int, value int) error {
+						if num != value || seen[num] {
+							return fmt.Errorf("line %d is %d", num, value)
+						}
+						if ordered && num != last+1 {
+							return fmt.Errorf("line %d after %d", num, last)
+						}
+						seen[num], last = true, num
+						return nil
+					})
+				if err != nil {
+					t.Fatal(chunk, ordered, err)
+				}
+				for i := 1; i <= 5000; i++ {
+					if !seen[i] {
+						t.Fatal(chunk, ordered, "missing line", i)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestParallelLinesError(t *testing.T) {
+	name := makeLines(t, 10000, true)
+	failed := errors.New("failed")
+	var calls int64
+	err := ParallelEach(context.Background(), name, ParallelOptions{Workers: 4, ChunkSize: 1024}, func(num int, line []byte) error {
+		atomic.AddInt64(&calls, 1)
+		if num == 100 {
+			return failed
+		}
+		return nil
+	})
+	if err != failed || atomic.LoadInt64(&calls) == 10000 {
+		t.Error(err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ParallelEach(ctx, name, ParallelOptions{}, func(int, []byte) error { return nil }); err != context.Canceled {
+		t.Error(err)
+	}
+	if err := ParallelEach(context.Background(), name+".none", ParallelOptions{}, nil); !os.IsNotExist(err) {
+		t.Error(err)
+	}
+}
+
+func BenchmarkReadBytesLine(b *testing.B) {
+	name := makeLines(b, 500000, true)
+	info, _ := os.Stat(name)
+	b.SetBytes(info.Size())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var fields int
+		ReadBytesLine(name, func(line []byte) {
+			fields += strings.Count(string(line), "\t")
+		})
+	}
+}
+
+func BenchmarkParallelLines(b *testing.B) {
+	name := makeLines(b, 500000, true)
+	info, _ := os.Stat(name)
+	b.SetBytes(info.Size())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var fields int64
+		ParallelEach(context.Background(), name, ParallelOptions{ChunkSize: MB}, func(num int, line []byte) error {
+			atomic.AddInt64(&fields, int64(strings.Count(string(line), "\t")))
+			return nil
+		})
+	}
+}
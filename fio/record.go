@@ -0,0 +1,496 @@
+package fio
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RecordOptions Options of RecordReader and RecordWriter
+type RecordOptions struct {
+	Comma      rune // field delimiter, ',' by default, OpenRecords uses '\t' for .tsv and .tab
+	Comment    rune // lines starting with it are skipped if not 0
+	NoHeader   bool // the first line is data, columns are named by index "0", "1", ...
+	TrimSpace  bool // trim leading and trailing spaces of fields
+	LazyQuotes bool // allow quotes in unquoted fields
+}
+
+// RowError Error of converting a field, with line number in file
+type RowError struct {
+	Line   int
+	Column string
+	Value  string
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d, column %s: cannot convert %q: %v", e.Line, e.Column, e.Value, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// RowErrors All errors of ReadRecords
+type RowErrors []*RowError
+
+func (es RowErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Field of struct mapped to a column
+type fieldMap struct {
+	index  []int // field index of reflect
+	column int
+	name   string
+}
+
+// RecordReader Read CSV or TSV records, quoted fields may contain delimiters and linebreaks
+// Rows are decoded into structs by tags like `col:"price"`, untagged fields match by name ignoring case
+//
+//	rr, _ := NewRecordReader(r, RecordOptions{Comma: '\t'})
+//	for rr.Next() {
+//		var item Item
+//		if err := rr.Decode(&item); err != nil { ... }
+//	}
+//	err := rr.Err()
+type RecordReader struct {
+	r      *csv.Reader
+	opt    RecordOptions
+	header []string
+	index  map[string]int
+	record []string
+	first  []string // first row when NoHeader is set
+	line   int
+	err    error
+	fields map[reflect.Type][]fieldMap
+}
+
+// NewRecordReader Create reader on r and read the header line
+func NewRecordReader(r io.Reader, opt RecordOptions) (*RecordReader, error) {
+	cr := csv.NewReader(r)
+	if opt.Comma != 0 {
+		cr.Comma = opt.Comma
+	}
+	cr.Comment = opt.Comment
+	cr.LazyQuotes = opt.LazyQuotes
+	cr.TrimLeadingSpace = opt.TrimSpace
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	rr := &RecordReader{r: cr, opt: opt, index: make(map[string]int), fields: make(map[reflect.Type][]fieldMap)}
+	header, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			rr.header = []string{}
+			return rr, nil
+		}
+		return nil, err
+	}
+	if opt.NoHeader {
+		rr.first = append([]string(nil), header...)
+		if opt.TrimSpace {
+			for i := range rr.first {
+				rr.first[i] = strings.TrimSpace(rr.first[i])
+			}
+		}
+		for i := range header {
+			rr.header = append(rr.header, strconv.Itoa(i))
+		}
+	} else {
+		for _, h := range header {
+			rr.header = append(rr.header, strings.TrimSpace(strings.TrimPrefix(h, BOM)))
+		}
+	}
+	for i, h := range rr.header {
+		if _, ok := rr.index[h]; !ok {
+			rr.index[h] = i
+		}
+	}
+	return rr, nil
+}
+
+// OpenRecords Open file as records, compressed file is decoded and '\t' is the delimiter of .tsv or .tab
+func OpenRecords(path string, opt RecordOptions) (*RecordReader, *os.File, error) {
+	file, reader, err := MakeReader(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opt.Comma == 0 {
+		opt.Comma = commaOf(path)
+	}
+	rr, err := NewRecordReader(reader, opt)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return rr, file, nil
+}
+
+// Delimiter by extension, extension of codec is ignored, e.g. a.tsv.gz
+func commaOf(path string) rune {
+	if CodecByExt(path) != nil {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsv", ".tab":
+		return '\t'
+	}
+	return ','
+}
+
+// Header Names of columns
+func (rr *RecordReader) Header() []string {
+	return rr.header
+}
+
+// Next Advance to the next record, false at the end or on error
+func (rr *RecordReader) Next() bool {
+	if rr.err != nil {
+		return false
+	}
+	if rr.first != nil {
+		rr.record, rr.first = rr.first, nil
+		rr.line = 1
+		return true
+	}
+	record, err := rr.r.Read()
+	if err != nil {
+		if err != io.EOF {
+			rr.err = err
+		}
+		rr.record = nil
+		return false
+	}
+	rr.line, _ = rr.r.FieldPos(0)
+	if rr.opt.TrimSpace {
+		for i := range record {
+			record[i] = strings.TrimSpace(record[i])
+		}
+	}
+	rr.record = record
+	return true
+}
+
+// Record Fields of current record, valid until the next call of Next
+func (rr *RecordReader) Record() []string {
+	return rr.record
+}
+
+// Line Line number where current record starts
+func (rr *RecordReader) Line() int {
+	return rr.line
+}
+
+// Err First error except io.EOF
+func (rr *RecordReader) Err() error {
+	return rr.err
+}
+
+// Get Field of column name in current record, empty if no such column
+func (rr *RecordReader) Get(name string) string {
+	if i, ok := rr.index[name]; ok && i < len(rr.record) {
+		return rr.record[i]
+	}
+	return ""
+}
+
+// Decode Convert current record into struct pointed by v
+// All fields are converted, and the first *RowError is returned
+func (rr *RecordReader) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("fio: Decode needs pointer to struct, not %T", v)
+	}
+	rv = rv.Elem()
+	fields, err := rr.mapFields(rv.Type())
+	if err != nil {
+		return err
+	}
+	var first error
+	for _, f := range fields {
+		if f.column >= len(rr.record) {
+			continue
+		}
+		s := rr.record[f.column]
+		if err := setField(rv.FieldByIndex(f.index), s); err != nil && first == nil {
+			first = &RowError{Line: rr.line, Column: f.name, Value: s, Err: err}
+		}
+	}
+	return first
+}
+
+// Map fields of struct type to columns of header
+func (rr *RecordReader) mapFields(t reflect.Type) ([]fieldMap, error) {
+	if fields, ok := rr.fields[t]; ok {
+		return fields, nil
+	}
+	var fields []fieldMap
+	for _, f := range structFields(t) {
+		column, ok := rr.index[f.name]
+		if !ok && !f.tagged {
+			for i, h := range rr.header {
+				if strings.EqualFold(h, f.name) {
+					column, ok = i, true
+					break
+				}
+			}
+		}
+		if !ok {
+			if f.tagged {
+				return nil, fmt.Errorf("fio: column %q of %s not found", f.name, t)
+			}
+			continue
+		}
+		fields = append(fields, fieldMap{index: f.index, column: column, name: f.name})
+	}
+	rr.fields[t] = fields
+	return fields, nil
+}
+
+type structField struct {
+	index  []int
+	name   string
+	tagged bool
+}
+
+// Exported fields of struct with their column names, `col:"-"` is skipped
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("col")
+		if tag == "-" {
+			continue
+		}
+		fields = append(fields, structField{index: f.Index, name: f.Name, tagged: tag != ""})
+		if tag != "" {
+			fields[len(fields)-1].name = tag
+		}
+	}
+	return fields
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Convert s into v, empty string is zero value or nil pointer
+func setField(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if s == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if s == "" && v.Kind() != reflect.String {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// ReadRecords Read all rows of file into structs of T
+// Rows failing to convert are skipped and their errors are returned together as RowErrors
+func ReadRecords[T any](path string, opt RecordOptions) ([]T, error) {
+	rr, file, err := OpenRecords(path, opt)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rows []T
+	var errs RowErrors
+	for rr.Next() {
+		var row T
+		if err := rr.Decode(&row); err != nil {
+			re, ok := err.(*RowError)
+			if !ok {
+				return rows, err
+			}
+			errs = append(errs, re)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if rr.Err() != nil {
+		return rows, rr.Err()
+	}
+	if len(errs) > 0 {
+		return rows, errs
+	}
+	return rows, nil
+}
+
+// WriteRecords Write rows into file atomically by WriteFile, '\t' is the delimiter of .tsv or .tab
+func WriteRecords[T any](path string, rows []T, opt RecordOptions) error {
+	if opt.Comma == 0 {
+		opt.Comma = commaOf(path)
+	}
+	var buf bytes.Buffer
+	rw := NewRecordWriter(&buf, opt)
+	for i := range rows {
+		if err := rw.Encode(&rows[i]); err != nil {
+			return err
+		}
+	}
+	if err := rw.Flush(); err != nil {
+		return err
+	}
+	return WriteFile(path, buf.Bytes())
+}
+
+// RecordWriter Write CSV or TSV records, fields are quoted when needed
+type RecordWriter struct {
+	w      *csv.Writer
+	opt    RecordOptions
+	header bool
+	record []string
+}
+
+// NewRecordWriter Create writer on w, Flush must be called at the end
+func NewRecordWriter(w io.Writer, opt RecordOptions) *RecordWriter {
+	cw := csv.NewWriter(w)
+	if opt.Comma != 0 {
+		cw.Comma = opt.Comma
+	}
+	return &RecordWriter{w: cw, opt: opt}
+}
+
+// Write One record of fields
+func (rw *RecordWriter) Write(record []string) error {
+	rw.header = true
+	return rw.w.Write(record)
+}
+
+// Encode Write struct pointed by v or struct value as a record,
+// the header of column names is written before the first record unless NoHeader is set
+func (rw *RecordWriter) Encode(v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("fio: Encode needs struct, not %T", v)
+	}
+	fields := structFields(rv.Type())
+	if !rw.header && !rw.opt.NoHeader {
+		header := make([]string, len(fields))
+		for i, f := range fields {
+			header[i] = f.name
+		}
+		if err := rw.Write(header); err != nil {
+			return err
+		}
+	}
+	rw.record = rw.record[:0]
+	for _, f := range fields {
+		s, err := formatField(rv.FieldByIndex(f.index))
+		if err != nil {
+			return fmt.Errorf("fio: column %s: %w", f.name, err)
+		}
+		rw.record = append(rw.record, s)
+	}
+	return rw.Write(rw.record)
+}
+
+// Flush Write buffered records into the underlying writer
+func (rw *RecordWriter) Flush() error {
+	rw.w.Flush()
+	return rw.w.Error()
+}
+
+// Convert field into text, nil pointer is empty
+func formatField(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String(), nil
+	case v.Type() == timeType:
+		return v.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	case v.Type().Implements(textMarshalerType):
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	case v.CanAddr() && v.Addr().Type().Implements(textMarshalerType):
+		b, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
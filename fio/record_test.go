@@ -0,0 +1,140 @@
+package fio
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type item struct {
+	Name    string        `col:"name"`
+	Price   float64       `col:"price"`
+	Count   int           // matched by name ignoring case
+	Timeout time.Duration `col:"timeout"`
+	Since   *time.Time    `col:"since"`
+	Note    string        `col:"-"`
+	hidden  int
+}
+
+func TestRecordReader(t *testing.T) {
+	input := "name,price,COUNT,timeout,since,extra\n" +
+		"apple,1.5,3,1s,2020-01-02T03:04:05Z,x\n" +
+		"\"pear, green\",2,,,,\n" +
+		"\"multi\nline\",x,y,2s,,\n" +
+		"last,4,5\n"
+	rr, err := NewRecordReader(strings.NewReader(input), RecordOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(rr.Header(), "|") != "name|price|COUNT|timeout|since|extra" {
+		t.Error(rr.Header())
+	}
+
+	var items []item
+	var lines []int
+	for rr.Next() {
+		var it item
+		err := rr.Decode(&it)
+		lines = append(lines, rr.Line())
+		if rr.Line() == 4 {
+			var re *RowError
+			if !errors.As(err, &re) || re.Line != 4 || re.Column != "price" || re.Value != "x" || !errors.Is(err, strconv.ErrSyntax) {
+				t.Error(err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, it)
+	}
+	if rr.Err() != nil {
+		t.Fatal(rr.Err())
+	}
+	if len(lines) != 4 || lines[0] != 2 || lines[2] != 4 || lines[3] != 6 {
+		t.Error(lines)
+	}
+	since := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if len(items) != 3 || items[0].Name != "apple" || items[0].Price != 1.5 || items[0].Count != 3 ||
+		items[0].Timeout != time.Second || !items[0].Since.Equal(since) {
+		t.Errorf("%+v", items)
+	}
+	if items[1].Name != "pear, green" || items[1].Since != nil || items[2].Count != 5 {
+		t.Errorf("%+v", items)
+	}
+
+	type missing struct {
+		Weight int `col:"weight"`
+	}
+	rr, _ = NewRecordReader(strings.NewReader("a\n1\n"), RecordOptions{})
+	if !rr.Next() || rr.Decode(&missing{}) == nil || rr.Decode(missing{}) == nil {
+		t.Error("weight is not in header")
+	}
+}
+
+func TestRecordNoHeader(t *testing.T) {
+	type pair struct {
+		Key   string `col:"0"`
+		Value int    `col:"1"`
+	}
+	rr, err := NewRecordReader(strings.NewReader("a\t 1\n# comment\nb\t2\n"), RecordOptions{Comma: '\t', Comment: '#', NoHeader: true, TrimSpace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pairs []pair
+	for rr.Next() {
+		var p pair
+		if err := rr.Decode(&p); err != nil {
+			t.Fatal(err)
+		}
+		pairs = append(pairs, p)
+	}
+	if len(pairs) != 2 || pairs[0] != (pair{"a", 1}) || pairs[1] != (pair{"b", 2}) {
+		t.Error(pairs)
+	}
+}
+
+func TestRecordFile(t *testing.T) {
+	since := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []item{
+		{Name: "tab\there", Price: 0.1, Count: 1, Timeout: time.Minute, Since: &since, Note: "skipped"},
+		{Name: "quote\"d", Price: 2},
+	}
+	for _, name := range []string{"a.csv", "a.tsv.gz"} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := WriteRecords(path, rows, RecordOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadRecords[item](path, RecordOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].Name != "tab\there" || got[0].Timeout != time.Minute || !got[0].Since.Equal(since) ||
+			got[0].Note != "" || got[1].Name != "quote\"d" || got[1].Since != nil {
+			t.Errorf("%s: %+v", name, got)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "bad.tsv")
+	WriteFile(path, "name\tprice\na\t1\nb\tx\nc\t3\nd\ty\n")
+	type priced struct {
+		Name  string  `col:"name"`
+		Price float64 `col:"price"`
+	}
+	prices, err := ReadRecords[priced](path, RecordOptions{})
+	var errs RowErrors
+	if len(prices) != 2 || !errors.As(err, &errs) || len(errs) != 2 || errs[0].Line != 3 || errs[1].Line != 5 {
+		t.Error(prices, err)
+	}
+
+	var buf bytes.Buffer
+	rw := NewRecordWriter(&buf, RecordOptions{NoHeader: true})
+	rw.Encode(item{Name: "x"})
+	if rw.Flush(); buf.String() != "x,0,0,0s,\n" {
+		t.Errorf("%q", buf.String())
+	}
+}
@@ -0,0 +1,247 @@
+package fio
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Where Tail starts
+const (
+	TailEnd    int = iota // only lines written from now on
+	TailStart  int = iota // all lines in file
+	TailLines  int = iota // the last TailOptions.Lines lines
+	TailOffset int = iota // resume at TailOptions.State saved from TailLine.State
+)
+
+// Default polling interval of Tail
+const TAIL_INTERVAL = 250 * time.Millisecond
+
+// TailOptions Options of Tail, zero value follows new lines by polling
+type TailOptions struct {
+	From     int           // TailEnd, TailStart, TailLines or TailOffset
+	Lines    int           // number of lines for TailLines
+	State    TailState     // for TailOffset, file is read from start if it's another file or shorter
+	Interval time.Duration // how often file is checked, TAIL_INTERVAL if 0
+	Inotify  bool          // wake up by inotify on linux, polling is still used as fallback
+}
+
+// TailState Position after a line and identity of the file it's in, save it to resume by TailOffset
+// Dev and Ino are 0 where the identity of file is unknown
+type TailState struct {
+	Offset int64
+	Dev    uint64
+	Ino    uint64
+}
+
+// TailLine One line without linebreak, and the position after it
+// Lines of old file delivered after rotation have State of the old file
+type TailLine struct {
+	Text  string
+	State TailState
+}
+
+// Wakes up Tail when the directory of file changes
+type notifier interface {
+	C() <-chan struct{}
+	Close() error
+}
+
+// Tail Follow file at path like tail -F, call fn for every complete line until ctx is done
+// Rotation (path renamed and created again) is detected by inode, the rest of old file is read
+// before switching to the new one, and the file is read from start if it's truncated
+// Error of fn stops tailing and is returned except ErrStop, ctx.Err() is returned when it's done
+func Tail(ctx context.Context, path string, opt TailOptions, fn func(line TailLine) error) error {
+	err := tail(ctx, path, opt, fn)
+	if err == ErrStop {
+		return nil
+	}
+	return err
+}
+
+func tail(ctx context.Context, path string, opt TailOptions, fn func(line TailLine) error) error {
+	if opt.Interval <= 0 {
+		opt.Interval = TAIL_INTERVAL
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { file.Close() }()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	dev, ino := fileID(file)
+	offset, err := tailOffset(file, info.Size(), dev, ino, opt)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	var notify <-chan struct{}
+	if opt.Inotify {
+		if n, err := newNotifier(filepath.Dir(path)); err == nil && n != nil {
+			defer n.Close()
+			notify = n.C()
+		}
+	}
+
+	r := bufio.NewReaderSize(file, IO_BUF_SIZE)
+	var partial []byte
+	// deliver complete lines until EOF, and the unfinished last line if final
+	read := func(final bool) error {
+		for {
+			frag, err := r.ReadSlice(LINE_END)
+			partial = append(partial, frag...)
+			offset += int64(len(frag))
+			switch err {
+			case nil:
+			case bufio.ErrBufferFull:
+				continue
+			case io.EOF:
+				if !final || len(partial) == 0 {
+					return nil
+				}
+			default:
+				return err
+			}
+			line := TailLine{Text: string(trimEOL(partial)), State: TailState{offset, dev, ino}}
+			partial = partial[:0]
+			if err := fn(line); err != nil {
+				return err
+			}
+			if final {
+				return nil
+			}
+		}
+	}
+
+	timer := time.NewTimer(opt.Interval)
+	defer timer.Stop()
+	for {
+		if err := read(false); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-notify:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(opt.Interval)
+		case <-timer.C:
+			timer.Reset(opt.Interval)
+		}
+
+		if stat, err := os.Stat(path); err == nil && !os.SameFile(stat, info) {
+			if err := read(false); err != nil {
+				return err
+			}
+			next, err := os.Open(path)
+			if err != nil {
+				continue // not created yet
+			}
+			if info, err = next.Stat(); err != nil {
+				next.Close()
+				continue
+			}
+			if err := read(true); err != nil {
+				next.Close()
+				return err
+			}
+			file.Close()
+			file, offset = next, 0
+			dev, ino = fileID(file)
+			r.Reset(file)
+			partial = partial[:0]
+			continue
+		}
+		if stat, err := file.Stat(); err == nil && stat.Size() < offset {
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			offset = 0
+			r.Reset(file)
+			partial = partial[:0]
+		}
+	}
+}
+
+// TailChan Tail with lines sent to channel, the error channel receives the result of Tail
+// and both are closed when it stops
+func TailChan(ctx context.Context, path string, opt TailOptions) (<-chan TailLine, <-chan error) {
+	lines := make(chan TailLine, 64)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(lines)
+		errc <- Tail(ctx, path, opt, func(line TailLine) error {
+			select {
+			case lines <- line:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+	return lines, errc
+}
+
+// Start offset of Tail in file of size, whose identity is dev and ino
+func tailOffset(file *os.File, size int64, dev, ino uint64, opt TailOptions) (int64, error) {
+	switch opt.From {
+	case TailStart:
+		return 0, nil
+	case TailLines:
+		return lastLines(file, size, opt.Lines)
+	case TailOffset:
+		st := opt.State
+		if (st.Dev != 0 || st.Ino != 0) && (dev != 0 || ino != 0) && (st.Dev != dev || st.Ino != ino) {
+			return 0, nil // rotated
+		}
+		if st.Offset < 0 || st.Offset > size {
+			return 0, nil // truncated or replaced
+		}
+		return st.Offset, nil
+	}
+	return size, nil
+}
+
+// Offset where the last n lines start, the linebreak at the end of file doesn't start a line
+func lastLines(file *os.File, size int64, n int) (int64, error) {
+	if n <= 0 {
+		return size, nil
+	}
+	var buf [IO_BUF_SIZE]byte
+	end := size
+	for end > 0 {
+		start := end - IO_BUF_SIZE
+		if start < 0 {
+			start = 0
+		}
+		block := buf[:end-start]
+		if _, err := file.ReadAt(block, start); err != nil && err != io.EOF {
+			return 0, err
+		}
+		for i := len(block) - 1; i >= 0; i-- {
+			if block[i] != LINE_END || start+int64(i) == size-1 {
+				continue
+			}
+			if n--; n == 0 {
+				return start + int64(i) + 1, nil
+			}
+		}
+		end = start
+	}
+	return 0, nil
+}
@@ -0,0 +1,52 @@
+//go:build linux
+
+package fio
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Notifier by inotify watching changes of files in a directory
+type inotify struct {
+	file *os.File
+	c    chan struct{}
+}
+
+func newNotifier(dir string) (notifier, error) {
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	mask := uint32(unix.IN_MODIFY | unix.IN_CREATE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DELETE | unix.IN_CLOSE_WRITE)
+	if _, err := unix.InotifyAddWatch(fd, dir, mask); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	n := &inotify{file: os.NewFile(uintptr(fd), "inotify"), c: make(chan struct{}, 1)}
+	go n.loop()
+	return n, nil
+}
+
+// Events are only used to wake up, so they are not parsed
+func (n *inotify) loop() {
+	var buf [4096]byte
+	for {
+		if _, err := n.file.Read(buf[:]); err != nil {
+			return
+		}
+		select {
+		case n.c <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (n *inotify) C() <-chan struct{} {
+	return n.c
+}
+
+func (n *inotify) Close() error {
+	return n.file.Close()
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package fio
+
+// No inotify, Tail polls only
+func newNotifier(dir string) (notifier, error) {
+	return nil, nil
+}
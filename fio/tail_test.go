@@ -0,0 +1,174 @@
+package fio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func appendFile(t *testing.T, path string, s string) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(s)
+	file.Close()
+}
+
+func expectLines(t *testing.T, ctx context.Context, lines <-chan TailLine, want ...string) TailLine {
+	t.Helper()
+	var last TailLine
+	for _, w := range want {
+		select {
+		case last = <-lines:
+			if last.Text != w {
+				t.Fatalf("%q != %q", last.Text, w)
+			}
+		case <-ctx.Done():
+			t.Fatal("timeout waiting", w)
+		}
+	}
+	return last
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "1\n2\n3\n")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	lines, errc := TailChan(ctx, path, TailOptions{From: TailLines, Lines: 2, Interval: 10 * time.Millisecond})
+	expectLines(t, ctx, lines, "2", "3")
+
+	appendFile(t, path, "4\r\n5")
+	expectLines(t, ctx, lines, "4")
+	time.Sleep(30 * time.Millisecond)
+	appendFile(t, path, "x\n")
+	last := expectLines(t, ctx, lines, "5x")
+	if last.State.Offset != 12 {
+		t.Error(last.State)
+	}
+
+	// rotated, the unfinished line of old file is delivered
+	appendFile(t, path, "6")
+	os.Rename(path, path+".1")
+	appendFile(t, path, "7\n")
+	old := expectLines(t, ctx, lines, "6")
+	rotated := expectLines(t, ctx, lines, "7")
+	if rotated.State.Offset != 2 || (runtime.GOOS == "linux" && old.State.Ino == rotated.State.Ino) {
+		t.Error(old.State, rotated.State)
+	}
+
+	// truncated
+	time.Sleep(30 * time.Millisecond)
+	os.WriteFile(path, nil, 0644)
+	time.Sleep(30 * time.Millisecond)
+	appendFile(t, path, "8\n")
+	expectLines(t, ctx, lines, "8")
+
+	cancel()
+	if err := <-errc; err != context.Canceled {
+		t.Error(err)
+	}
+	if _, ok := <-lines; ok {
+		t.Error("lines is not closed")
+	}
+}
+
+func TestTailResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "1\n2\n3\n")
+	stop := func(line TailLine) error {
+		if line.Text == "2" {
+			return ErrStop
+		}
+		return nil
+	}
+	var state TailState
+	err := Tail(context.Background(), path, TailOptions{From: TailStart}, func(line TailLine) error {
+		state = line.State
+		return stop(line)
+	})
+	if err != nil || state.Offset != 4 {
+		t.Fatal(err, state)
+	}
+
+	var got []string
+	collect := func(line TailLine) error {
+		got = append(got, line.Text)
+		if line.Text == "3" {
+			return ErrStop
+		}
+		return nil
+	}
+	Tail(context.Background(), path, TailOptions{From: TailOffset, State: state}, collect)
+	if len(got) != 1 {
+		t.Error(got)
+	}
+	// file is shorter than offset, so it's read from start
+	got = nil
+	Tail(context.Background(), path, TailOptions{From: TailOffset, State: TailState{Offset: 100, Dev: state.Dev, Ino: state.Ino}}, collect)
+	if len(got) != 3 {
+		t.Error(got)
+	}
+
+	for n, want := range map[int]int64{0: 6, 1: 4, 3: 0, 9: 0} {
+		file, _ := os.Open(path)
+		if off, err := lastLines(file, 6, n); off != want || err != nil {
+			t.Error(n, off, err)
+		}
+		file.Close()
+	}
+}
+
+func TestTailResumeRotated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "1\n2\n3\n")
+	var state TailState
+	Tail(context.Background(), path, TailOptions{From: TailStart}, func(line TailLine) error {
+		state = line.State
+		if line.Text == "2" {
+			return ErrStop
+		}
+		return nil
+	})
+	if state.Offset != 4 || (runtime.GOOS == "linux" && state.Ino == 0) {
+		t.Fatal(state)
+	}
+
+	// rotated while stopped, the new file has grown past the saved offset
+	os.Rename(path, path+".1")
+	appendFile(t, path, "a\nb\nc\n")
+	var got []string
+	Tail(context.Background(), path, TailOptions{From: TailOffset, State: state}, func(line TailLine) error {
+		got = append(got, line.Text)
+		if line.Text == "c" {
+			return ErrStop
+		}
+		return nil
+	})
+	if strings.Join(got, ",") != "a,b,c" {
+		t.Error(got)
+	}
+}
+
+func TestTailInotify(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("inotify is only on linux")
+	}
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// polling never happens in this test
+	lines, _ := TailChan(ctx, path, TailOptions{Interval: time.Hour, Inotify: true})
+	time.Sleep(20 * time.Millisecond)
+	appendFile(t, path, "1\n")
+	expectLines(t, ctx, lines, "1")
+	appendFile(t, path, "2\n")
+	expectLines(t, ctx, lines, "2")
+}
@@ -1,5 +1,9 @@
 module github.com/alexloser/goaux
 
-go 1.20
+go 1.21
 
-require golang.org/x/text v0.14.0
+require (
+	golang.org/x/sys v0.15.0
+	golang.org/x/term v0.15.0
+	golang.org/x/text v0.14.0
+)
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"sync"
 )
 
 const (
@@ -50,6 +51,30 @@ func RegistSignalHandler(handler func(os.Signal), signals ...os.Signal) {
 	}()
 }
 
+// a signal notifier which keeps listening for repeated signals, call stop to unregister
+func RegistSignalLoop(handler func(os.Signal), signals ...os.Signal) (stop func()) {
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, signals...)
+	go func() {
+		for {
+			select {
+			case s := <-c:
+				handler(s)
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
+
 // Return stack list for printing
 func StackInfo(full bool) string {
 	var buf [4096]byte
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/alexloser/goaux/fs"
 )
@@ -64,3 +65,25 @@ func TestSystem(t *testing.T) {
 		}
 	}
 }
+
+func TestRegistSignalLoop(t *testing.T) {
+	if IsWindows() {
+		t.Skip("no signal to self on windows")
+	}
+	got := make(chan os.Signal, 2)
+	stop := RegistSignalLoop(func(s os.Signal) { got <- s }, os.Interrupt)
+	defer stop()
+
+	self, _ := os.FindProcess(os.Getpid())
+	for i := 0; i < 2; i++ {
+		self.Signal(os.Interrupt)
+		select {
+		case s := <-got:
+			if s != os.Interrupt {
+				t.Error(s)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("signal", i)
+		}
+	}
+}